@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/sushichan044/mdfm"
+	"github.com/sushichan044/mdfm/internal/diff"
+)
+
+type (
+	setCmd struct {
+		Pattern     string   `arg:"" name:"pattern" help:"Glob pattern of the files to edit (eg. 'docs/**/*.md')."`
+		Assignments []string `arg:"" name:"key=value" help:"Keys to set and their value. Nested keys are joined with '.' (eg. 'seo.title=Hello')."`
+
+		Type string `name:"type" enum:"string,int,bool,list" default:"string" help:"Type of the values: string, int, bool or list (comma-separated items)."`
+
+		editFlags `embed:""`
+	}
+
+	unsetCmd struct {
+		Pattern string   `arg:"" name:"pattern" help:"Glob pattern of the files to edit (eg. 'docs/**/*.md')."`
+		Keys    []string `arg:"" name:"key" help:"Keys to remove. Nested keys are joined with '.' (eg. 'seo.legacy_id')."`
+
+		editFlags `embed:""`
+	}
+
+	renameKeyCmd struct {
+		Pattern string `arg:"" name:"pattern" help:"Glob pattern of the files to edit (eg. 'docs/**/*.md')."`
+		From    string `arg:"" name:"from" help:"Key to rename. Nested keys are joined with '.' (eg. 'seo.desc')."`
+		To      string `arg:"" name:"to" help:"New name of the key (eg. 'seo.description')."`
+
+		Merge bool `name:"merge" help:"Combine the values when a file already has the new key: lists are concatenated, mappings merged."`
+
+		editFlags `embed:""`
+	}
+
+	// editFlags are the flags shared by the commands that modify files.
+	editFlags struct {
+		DryRun bool `name:"dry-run" help:"Print a unified diff of the changes instead of writing the files."`
+
+		formatFlags `embed:""`
+	}
+)
+
+func (cmd *setCmd) Run() error {
+	type assignment struct {
+		key   string
+		value any
+	}
+
+	assignments := make([]assignment, len(cmd.Assignments))
+	for i, arg := range cmd.Assignments {
+		key, raw, found := strings.Cut(arg, "=")
+		if !found || key == "" {
+			return fmt.Errorf("invalid assignment %q: expected KEY=VALUE", arg)
+		}
+		value, err := parseValue(raw, cmd.Type)
+		if err != nil {
+			return fmt.Errorf("invalid assignment %q: %w", arg, err)
+		}
+		assignments[i] = assignment{key: key, value: value}
+	}
+
+	return editFiles(cmd.Pattern, cmd.editFlags, func(fm *mdfm.OrderedMap) error {
+		for _, a := range assignments {
+			if err := fm.SetPath(a.key, a.value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (cmd *unsetCmd) Run() error {
+	return editFiles(cmd.Pattern, cmd.editFlags, func(fm *mdfm.OrderedMap) error {
+		for _, key := range cmd.Keys {
+			fm.DeletePath(key)
+		}
+		return nil
+	})
+}
+
+func (cmd *renameKeyCmd) Run() error {
+	opts, err := cmd.options()
+	if err != nil {
+		return err
+	}
+	if cmd.Merge {
+		opts = append(opts, mdfm.WithMergeValues())
+	}
+
+	changes, err := mdfm.RenameKey(cmd.Pattern, cmd.From, cmd.To, opts...)
+	if err != nil {
+		return err
+	}
+	return reportChanges(os.Stdout, os.Stderr, changes, cmd.DryRun)
+}
+
+// parseValue converts a value given on the command line to the frontmatter value of type typ.
+func parseValue(raw, typ string) (any, error) {
+	switch typ {
+	case "int":
+		i, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", raw)
+		}
+		return i, nil
+	case "bool":
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", raw)
+		}
+		return b, nil
+	case "list":
+		items := []any{}
+		if strings.TrimSpace(raw) == "" {
+			return items, nil
+		}
+		for item := range strings.SplitSeq(raw, ",") {
+			items = append(items, strings.TrimSpace(item))
+		}
+		return items, nil
+	default:
+		return raw, nil
+	}
+}
+
+// editFiles applies edit to the frontmatter of every file matched by pattern and writes the
+// changed files back, or prints their unified diff with --dry-run. Files without frontmatter
+// get a YAML frontmatter block if edit adds keys.
+func editFiles(pattern string, flags editFlags, edit func(fm *mdfm.OrderedMap) error) error {
+	opts, err := flags.options()
+	if err != nil {
+		return err
+	}
+
+	changes, err := mdfm.Edit(pattern, edit, opts...)
+	if err != nil {
+		return fmt.Errorf("error during glob %s: %w", pattern, err)
+	}
+	return reportChanges(os.Stdout, os.Stderr, changes, flags.DryRun)
+}
+
+// options returns the library options for the edit flags.
+func (f editFlags) options() ([]mdfm.Option, error) {
+	opts, err := f.formatFlags.options()
+	if err != nil {
+		return nil, err
+	}
+	if f.DryRun {
+		opts = append(opts, mdfm.WithDryRun())
+	}
+	return opts, nil
+}
+
+// reportChanges lists the updated files on w, or shows their unified diff with dryRun,
+// and prints errors and a summary on errW.
+func reportChanges(w, errW io.Writer, changes []mdfm.Change, dryRun bool) error {
+	wtr := bufio.NewWriter(w)
+
+	var hasErrors bool
+	var changed int
+	for _, change := range changes {
+		switch {
+		case change.Err != nil:
+			hasErrors = true
+			printProcessingError(errW, change.Path, change.Err)
+		case !change.Changed():
+		case dryRun:
+			changed++
+			fmt.Fprint(wtr, diff.Unified("a/"+change.Path, "b/"+change.Path, change.Before, change.After))
+		default:
+			changed++
+			fmt.Fprintln(wtr, change.Path)
+		}
+	}
+	if err := wtr.Flush(); err != nil && !errors.Is(err, syscall.EPIPE) {
+		return fmt.Errorf("error flushing output: %w", err)
+	}
+
+	verb := "updated"
+	if dryRun {
+		verb = "would be updated"
+	}
+	fmt.Fprintf(errW, "%d of %d files %s\n", changed, len(changes), verb)
+
+	if hasErrors {
+		return errors.New("errors occurred during editing markdown files")
+	}
+	return nil
+}
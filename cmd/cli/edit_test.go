@@ -0,0 +1,59 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		typ      string
+		expected any
+	}{
+		{name: "string", raw: " hello ", typ: "string", expected: " hello "},
+		{name: "untyped", raw: "42", typ: "", expected: "42"},
+		{name: "int", raw: "42", typ: "int", expected: int64(42)},
+		{name: "negative int with spaces", raw: " -7 ", typ: "int", expected: int64(-7)},
+		{name: "bool", raw: "true", typ: "bool", expected: true},
+		{name: "bool with spaces", raw: " false ", typ: "bool", expected: false},
+		{name: "short bool", raw: "0", typ: "bool", expected: false},
+		{name: "list", raw: "go,web", typ: "list", expected: []any{"go", "web"}},
+		{name: "list with spaces around items", raw: " go , web ,tools", typ: "list", expected: []any{"go", "web", "tools"}},
+		{name: "list with a single item", raw: "go", typ: "list", expected: []any{"go"}},
+		{name: "empty list", raw: "", typ: "list", expected: []any{}},
+		{name: "blank list", raw: "  ", typ: "list", expected: []any{}},
+		{name: "list with empty items", raw: "a,,b", typ: "list", expected: []any{"a", "", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := parseValue(tt.raw, tt.typ)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestParseValue_Invalid(t *testing.T) {
+	tests := []struct {
+		raw, typ string
+		expected string
+	}{
+		{raw: "12.5", typ: "int", expected: `"12.5" is not an integer`},
+		{raw: "", typ: "int", expected: `"" is not an integer`},
+		{raw: "99999999999999999999", typ: "int", expected: `"99999999999999999999" is not an integer`},
+		{raw: "yes", typ: "bool", expected: `"yes" is not a boolean`},
+		{raw: "", typ: "bool", expected: `"" is not a boolean`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.typ+" "+tt.raw, func(t *testing.T) {
+			_, err := parseValue(tt.raw, tt.typ)
+			require.EqualError(t, err, tt.expected)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/sushichan044/mdfm"
+)
+
+// Names of the fields of the documents printed by the query command, which --fields selects.
+// Other names select frontmatter keys.
+const (
+	fieldPath           = "path"
+	fieldBody           = "body"
+	fieldFrontMatter    = "frontMatter"
+	fieldFormat         = "format"
+	fieldRawFrontMatter = "rawFrontMatter"
+	fieldBodyStartLine  = "bodyStartLine"
+	fieldPositions      = "positions"
+
+	// frontMatterPrefix selects a frontmatter key named like a document field, eg. "frontMatter.path".
+	frontMatterPrefix = fieldFrontMatter + "."
+)
+
+// outputFields returns the fields selected by --fields or --body-only, or nil to print whole documents.
+func (cmd *queryCmd) outputFields() []string {
+	if cmd.BodyOnly {
+		return []string{fieldPath, fieldBody}
+	}
+	return cmd.Fields
+}
+
+// readsBody reports whether the body of documents is printed, and so has to be read.
+func (cmd *queryCmd) readsBody() bool {
+	if cmd.NoBody {
+		return false
+	}
+	fields := cmd.outputFields()
+	return fields == nil || slices.Contains(fields, fieldBody)
+}
+
+// readsPositions reports whether key positions are printed, and so have to be recorded.
+func (cmd *queryCmd) readsPositions() bool {
+	if fields := cmd.outputFields(); fields != nil {
+		return slices.Contains(fields, fieldPositions)
+	}
+	return cmd.Positions
+}
+
+// project returns the fields of the document at path, in the order they are given.
+func project(path string, doc *mdfm.MarkdownDocument[mdfm.OrderedMap], fields []string) *mdfm.OrderedMap {
+	om := mdfm.NewOrderedMap()
+	for _, field := range fields {
+		om.Set(field, fieldValue(path, doc, field))
+	}
+	return om
+}
+
+// fieldValue returns the value of a field of the document at path, or nil if it has no such field.
+func fieldValue(path string, doc *mdfm.MarkdownDocument[mdfm.OrderedMap], field string) any {
+	switch field {
+	case fieldPath:
+		return path
+	case fieldBody:
+		return doc.BodyString()
+	case fieldBodyStartLine:
+		return doc.BodyStartLine
+	case fieldPositions:
+		return doc.Positions
+	}
+
+	if doc.Format == "" {
+		return nil
+	}
+	switch field {
+	case fieldFrontMatter:
+		return mdfm.NormalizeJSON(doc.FrontMatter)
+	case fieldFormat:
+		return doc.Format
+	case fieldRawFrontMatter:
+		return string(doc.RawFrontMatter)
+	}
+
+	value, _ := doc.FrontMatter.GetPath(strings.TrimPrefix(field, frontMatterPrefix))
+	return mdfm.NormalizeJSON(value)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sushichan044/mdfm"
+	"github.com/sushichan044/mdfm/internal/codegen"
+)
+
+type (
+	genCmd struct {
+		Go genGoCmd `cmd:"" name:"go" help:"Generate a Go struct type for the frontmatter of matched files."`
+		TS genTSCmd `cmd:"" name:"ts" help:"Generate TypeScript types for the JSON output of mdfm, from matched files or a JSON Schema."`
+	}
+
+	genGoCmd struct {
+		Patterns []string `arg:"" name:"pattern" help:"Glob patterns to match (eg. '**/*.md'). Prefix with '!' to exclude matches (eg. '!**/drafts/**')."`
+
+		Package string   `name:"package" env:"GOPACKAGE" default:"main" help:"Package name of the generated file. Defaults to $GOPACKAGE when run by go generate."`
+		Type    string   `name:"type" default:"FrontMatter" help:"Name of the generated struct type."`
+		Tags    []string `name:"tags" default:"yaml,toml" help:"Struct tags to generate (eg. 'yaml,toml,json')."`
+		Output  string   `name:"output" short:"o" type:"path" help:"File to write the generated code to, instead of standard output."`
+
+		formatFlags `embed:""`
+	}
+
+	genTSCmd struct {
+		Patterns []string `arg:"" optional:"" name:"pattern" help:"Glob patterns to match (eg. '**/*.md'). Prefix with '!' to exclude matches (eg. '!**/drafts/**')."`
+
+		Schema       string `name:"schema" type:"existingfile" help:"Generate the types from this JSON Schema file instead of matched files."`
+		Zod          bool   `name:"zod" help:"Generate zod schemas and the types inferred from them instead of interfaces."`
+		Type         string `name:"type" default:"FrontMatter" help:"Name of the frontmatter type."`
+		DocumentType string `name:"document-type" default:"Document" help:"Name of the type of the documents printed by mdfm."`
+		Output       string `name:"output" short:"o" type:"path" help:"File to write the generated code to, instead of standard output."`
+
+		formatFlags `embed:""`
+	}
+)
+
+func (cmd *genGoCmd) Run() error {
+	schema, hasErrors, err := inferSchema(cmd.Patterns, cmd.formatFlags)
+	if err != nil {
+		return err
+	}
+	if hasErrors {
+		return errors.New("errors occurred during processing markdown files")
+	}
+
+	src, err := codegen.Go(schema, codegen.GoOptions{
+		Package:  cmd.Package,
+		TypeName: cmd.Type,
+		Tags:     cmd.Tags,
+		Source:   strings.Join(cmd.Patterns, " "),
+	})
+	if err != nil {
+		return err
+	}
+	return writeGenerated(cmd.Output, src)
+}
+
+func (cmd *genTSCmd) Run() error {
+	schema, description, err := cmd.jsonSchema()
+	if err != nil {
+		return err
+	}
+
+	src, err := codegen.TypeScript(schema, codegen.TypeScriptOptions{
+		TypeName:         cmd.Type,
+		DocumentTypeName: cmd.DocumentType,
+		Zod:              cmd.Zod,
+		Description:      description,
+	})
+	if err != nil {
+		return err
+	}
+	return writeGenerated(cmd.Output, src)
+}
+
+// jsonSchema returns the JSON Schema to generate the types from, read from --schema or
+// inferred from the matched files, and a description of where it comes from.
+func (cmd *genTSCmd) jsonSchema() (*mdfm.OrderedMap, string, error) {
+	switch {
+	case cmd.Schema != "" && len(cmd.Patterns) > 0:
+		return nil, "", errors.New("patterns cannot be used with --schema")
+	case cmd.Schema != "":
+		data, err := os.ReadFile(cmd.Schema)
+		if err != nil {
+			return nil, "", err
+		}
+		var schema mdfm.OrderedMap
+		if err = json.Unmarshal(data, &schema); err != nil {
+			return nil, "", fmt.Errorf("invalid schema %s: %w", cmd.Schema, err)
+		}
+		return &schema, "Frontmatter described by " + filepath.Base(cmd.Schema) + ".", nil
+	case len(cmd.Patterns) == 0:
+		return nil, "", errors.New("expected glob patterns or --schema")
+	}
+
+	schema, hasErrors, err := inferSchema(cmd.Patterns, cmd.formatFlags)
+	if err != nil {
+		return nil, "", err
+	}
+	if hasErrors {
+		return nil, "", errors.New("errors occurred during processing markdown files")
+	}
+	return schema.JSONSchema(), fmt.Sprintf("Frontmatter of the files matched by %q.", strings.Join(cmd.Patterns, " ")), nil
+}
+
+// writeGenerated writes generated code to the file at path, or to standard output if path is empty.
+func writeGenerated(path string, src []byte) error {
+	if path == "" {
+		_, err := os.Stdout.Write(src)
+		return err
+	}
+	if err := os.WriteFile(path, src, 0o644); err != nil { //nolint:gosec // Generated source files are meant to be readable.
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+	return nil
+}
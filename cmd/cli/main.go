@@ -7,9 +7,13 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
+	"strings"
 	"syscall"
 
+	"github.com/BurntSushi/toml"
 	"github.com/alecthomas/kong"
+	"gopkg.in/yaml.v2"
 
 	"github.com/sushichan044/mdfm"
 	"github.com/sushichan044/mdfm/version"
@@ -22,28 +26,92 @@ var (
 
 type (
 	CLI struct {
-		Pattern string `arg:"" name:"pattern" help:"Glob pattern to match (eg. '**/*.md')"`
+		Query queryCmd `cmd:"" default:"withargs" help:"Print the frontmatter and body of matched files as JSON (default command)."`
+		Set   setCmd   `cmd:"" help:"Set frontmatter keys in matched files."`
+		Unset unsetCmd `cmd:"" help:"Remove frontmatter keys from matched files."`
+
+		RenameKey renameKeyCmd `cmd:"" name:"rename-key" help:"Rename a frontmatter key in matched files."`
+		Validate  validateCmd  `cmd:"" help:"Check the frontmatter of matched files against a JSON Schema."`
+		Schema    schemaCmd    `cmd:"" help:"Infer the keys and types of the frontmatter of matched files."`
+		Gen       genCmd       `cmd:"" help:"Generate type definitions for the frontmatter of matched files."`
 
 		Version kong.VersionFlag `short:"v"`
 	}
 
+	queryCmd struct {
+		Patterns []string `arg:"" name:"pattern" help:"Glob patterns to match (eg. '**/*.md'). Prefix with '!' to exclude matches (eg. '!**/drafts/**')."`
+
+		RawFrontMatter bool `name:"raw-frontmatter" help:"Include the detected frontmatter format and the raw frontmatter text in the output."`
+		Positions      bool `name:"positions" help:"Include the body start line and the line and column of every frontmatter key in the output."`
+
+		Fields   []string `name:"fields" placeholder:"FIELD,..." xor:"projection" help:"Only output these fields, in this order: path, body, frontMatter, format, rawFrontMatter, bodyStartLine, positions or a frontmatter key (eg. 'title,seo.description,path'). Prefix keys named like a field with 'frontMatter.'."`
+		NoBody   bool     `name:"no-body" xor:"projection" help:"Omit the body from the output. Files are not read past their frontmatter."`
+		BodyOnly bool     `name:"body-only" xor:"projection" help:"Only output the path and body of files."`
+
+		Format     string `name:"format" enum:"ndjson,json,yaml,csv,tsv,table" default:"ndjson" help:"Output format: ndjson (one JSON object per line), json (an array), yaml, csv, tsv or table. csv, tsv and table print the columns selected with --fields."`
+		EscapeHTML bool   `name:"escape-html" negatable:"" default:"true" help:"Escape '<', '>' and '&' in JSON strings (eg. as \\u003c)."`
+
+		RequireFrontMatter bool   `name:"require-frontmatter" help:"Fail files that have no frontmatter block."`
+		Where              string `name:"where" placeholder:"EXPR" help:"Only output files matching the filter expression EXPR (eg. 'draft == false && \"go\" in tags'). Files that fail to parse are still reported."`
+
+		formatFlags `embed:""`
+	}
+
+	// formatFlags are the flags shared by all commands to configure frontmatter detection.
+	formatFlags struct {
+		CustomFormats []string `name:"custom-format" sep:"none" placeholder:"CODEC,START,END" help:"Detect frontmatter between custom START and END lines, decoded as CODEC (yaml, toml or json). Can be repeated (eg. 'yaml,<!-- ---,--- -->')."`
+	}
+
 	jsonPayload struct {
-		Body        string `json:"body"`
-		Path        string `json:"path"`
-		FrontMatter any    `json:"frontMatter"`
+		Body           *string `json:"body,omitempty"`
+		Path           string  `json:"path"`
+		FrontMatter    any     `json:"frontMatter"`
+		Format         string  `json:"format,omitempty"`
+		RawFrontMatter string  `json:"rawFrontMatter,omitempty"`
+
+		BodyStartLine int                      `json:"bodyStartLine,omitempty"`
+		Positions     map[string]mdfm.Position `json:"positions,omitempty"`
 	}
 )
 
-func (cmd *CLI) Run() error {
-	resultChan, globErr := mdfm.GlobStream[map[string]any](cmd.Pattern)
-	if globErr != nil {
-		return fmt.Errorf("error during glob %s: %w", cmd.Pattern, globErr)
+func (cmd *queryCmd) Run() error {
+	opts, optsErr := cmd.options()
+	if optsErr != nil {
+		return optsErr
+	}
+	opts = append(opts, mdfm.WithPatterns(cmd.Patterns[1:]...))
+	if cmd.readsPositions() {
+		opts = append(opts, mdfm.WithPositions())
+	}
+	if !cmd.readsBody() {
+		opts = append(opts, mdfm.WithoutBody())
+	}
+	if cmd.RequireFrontMatter {
+		opts = append(opts, mdfm.WithRequireFrontMatter())
+	}
+	if cmd.Where != "" {
+		filter, err := mdfm.CompileFilter(cmd.Where)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, mdfm.WithFilter(filter))
 	}
 
 	wtr := bufio.NewWriter(os.Stdout)
-	printer := newPassthroughPrinter(wtr)
+	printer, printerErr := newPrinter(wtr, cmd.Format, cmd.outputFields(), cmd.EscapeHTML)
+	if printerErr != nil {
+		return printerErr
+	}
+
+	resultChan, globErr := mdfm.GlobStream[mdfm.OrderedMap](cmd.Patterns[0], opts...)
+	if globErr != nil {
+		return fmt.Errorf("error during glob %s: %w", strings.Join(cmd.Patterns, " "), globErr)
+	}
 
 	defer func() {
+		if err := printer.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "error writing output on exit: %s\n", err)
+		}
 		if err := wtr.Flush(); err != nil {
 			if !errors.Is(err, syscall.EPIPE) {
 				fmt.Fprintf(os.Stderr, "error flushing output on exit: %s", err)
@@ -52,22 +120,16 @@ func (cmd *CLI) Run() error {
 	}()
 
 	var hasErrors bool
-	for task := range resultChan {
-		if task.Result.Err != nil {
+	for result := range resultChan {
+		if result.Err != nil {
 			hasErrors = true
-			fmt.Fprintf(os.Stderr, "error processing %s: %v\n", task.Metadata.Path, task.Result.Err)
+			printProcessingError(os.Stderr, result.Path, result.Err)
 			continue
 		}
 
-		payload := jsonPayload{
-			Body:        task.Result.Value.BodyString(),
-			Path:        task.Metadata.Path,
-			FrontMatter: task.Result.Value.FrontMatter,
-		}
-
-		if fmtErr := printer(payload); fmtErr != nil {
+		if fmtErr := printer.Print(cmd.document(result.Path, result.Document)); fmtErr != nil {
 			hasErrors = true
-			fmt.Fprintf(os.Stderr, "error formatting JSON for %s: %v\n", task.Metadata.Path, fmtErr)
+			fmt.Fprintf(os.Stderr, "error formatting output for %s: %v\n", result.Path, fmtErr)
 			continue
 		}
 
@@ -76,7 +138,7 @@ func (cmd *CLI) Run() error {
 				return nil
 			}
 			hasErrors = true
-			fmt.Fprintf(os.Stderr, "error flushing output for %s: %v\n", task.Metadata.Path, err)
+			fmt.Fprintf(os.Stderr, "error flushing output for %s: %v\n", result.Path, err)
 		}
 	}
 
@@ -87,15 +149,89 @@ func (cmd *CLI) Run() error {
 	return nil
 }
 
-// jsonPrinter writes a payload as JSON using a captured encoder.
-type jsonPrinter func(payload jsonPayload) error
+// document returns what is printed for the document at path: the fields selected by --fields,
+// or the whole document.
+func (cmd *queryCmd) document(path string, doc *mdfm.MarkdownDocument[mdfm.OrderedMap]) any {
+	if fields := cmd.outputFields(); fields != nil {
+		return project(path, doc, fields)
+	}
 
-func newPassthroughPrinter(output io.Writer) jsonPrinter {
-	enc := json.NewEncoder(output)
-	enc.SetIndent("", "  ")
+	payload := jsonPayload{Path: path}
+	if cmd.readsBody() {
+		body := doc.BodyString()
+		payload.Body = &body
+	}
+	if doc.Format != "" {
+		payload.FrontMatter = mdfm.NormalizeJSON(doc.FrontMatter)
+	}
+	if cmd.RawFrontMatter {
+		payload.Format = doc.Format
+		payload.RawFrontMatter = string(doc.RawFrontMatter)
+	}
+	if cmd.Positions {
+		payload.BodyStartLine = doc.BodyStartLine
+		payload.Positions = doc.Positions
+	}
+	return payload
+}
 
-	return func(payload jsonPayload) error {
-		return enc.Encode(payload)
+// options returns the library options for the custom formats.
+func (f formatFlags) options() ([]mdfm.Option, error) {
+	opts := make([]mdfm.Option, 0, len(f.CustomFormats))
+	for _, spec := range f.CustomFormats {
+		format, err := parseCustomFormat(spec)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, mdfm.WithFormats(format))
+	}
+	return opts, nil
+}
+
+// parseCustomFormat parses a --custom-format value of the form "codec,start,end".
+func parseCustomFormat(spec string) (mdfm.Format, error) {
+	parts := strings.SplitN(spec, ",", 3)
+	if len(parts) != 3 || strings.TrimSpace(parts[1]) == "" || strings.TrimSpace(parts[2]) == "" {
+		return mdfm.Format{}, fmt.Errorf("invalid custom format %q: expected CODEC,START,END", spec)
+	}
+
+	codec := strings.TrimSpace(parts[0])
+	format := mdfm.Format{Name: codec, Start: parts[1], End: parts[2], FieldTag: codec}
+	switch codec {
+	case mdfm.FormatYAML:
+		format.Unmarshal = yaml.Unmarshal
+	case mdfm.FormatTOML:
+		format.Unmarshal = toml.Unmarshal
+	case mdfm.FormatJSON:
+		format.Unmarshal = json.Unmarshal
+	default:
+		return mdfm.Format{}, fmt.Errorf("invalid custom format %q: unknown codec %q (want yaml, toml or json)", spec, codec)
+	}
+	return format, nil
+}
+
+// printProcessingError reports a per-file error.
+// Frontmatter errors are printed with their position and the offending line.
+func printProcessingError(w io.Writer, path string, err error) {
+	var parseErr *mdfm.ParseError
+	if !errors.As(err, &parseErr) {
+		msg := err.Error()
+		// Errors about the content of a file already start with its path.
+		if !strings.HasPrefix(msg, path+":") {
+			msg = path + ": " + msg
+		}
+		fmt.Fprintf(w, "error processing %s\n", msg)
+		return
+	}
+
+	fmt.Fprintf(w, "error processing %v\n", parseErr)
+	if parseErr.Snippet == "" {
+		return
+	}
+	fmt.Fprintf(w, "  %d | %s\n", parseErr.Line, parseErr.Snippet)
+	if parseErr.Column > 0 {
+		gutter := strings.Repeat(" ", len(strconv.Itoa(parseErr.Line)))
+		fmt.Fprintf(w, "  %s | %s^\n", gutter, strings.Repeat(" ", parseErr.Column-1))
 	}
 }
 
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCustomFormat(t *testing.T) {
+	tests := []struct {
+		spec       string
+		name       string
+		start, end string
+		data       string
+	}{
+		{spec: "yaml,<!--,-->", name: "yaml", start: "<!--", end: "-->", data: "title: Hello"},
+		{spec: "toml,%%%,%%%", name: "toml", start: "%%%", end: "%%%", data: `title = "Hello"`},
+		{spec: " json ,{{,}}", name: "json", start: "{{", end: "}}", data: `{"title": "Hello"}`},
+		{spec: "yaml,---,a,b", name: "yaml", start: "---", end: "a,b", data: "title: Hello"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			format, err := parseCustomFormat(tt.spec)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.name, format.Name)
+			assert.Equal(t, tt.start, format.Start)
+			assert.Equal(t, tt.end, format.End)
+			assert.Equal(t, tt.name, format.FieldTag)
+
+			var v map[string]any
+			require.NoError(t, format.Unmarshal([]byte(tt.data), &v))
+			assert.Equal(t, map[string]any{"title": "Hello"}, v)
+		})
+	}
+}
+
+func TestParseCustomFormat_Invalid(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected string
+	}{
+		{spec: "", expected: `invalid custom format "": expected CODEC,START,END`},
+		{spec: "yaml", expected: `invalid custom format "yaml": expected CODEC,START,END`},
+		{spec: "yaml,<!--", expected: `invalid custom format "yaml,<!--": expected CODEC,START,END`},
+		{spec: "yaml, ,-->", expected: `invalid custom format "yaml, ,-->": expected CODEC,START,END`},
+		{spec: "yaml,<!--,", expected: `invalid custom format "yaml,<!--,": expected CODEC,START,END`},
+		{
+			spec:     "hcl,<!--,-->",
+			expected: `invalid custom format "hcl,<!--,-->": unknown codec "hcl" (want yaml, toml or json)`,
+		},
+		{
+			spec:     ",<!--,-->",
+			expected: `invalid custom format ",<!--,-->": unknown codec "" (want yaml, toml or json)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			_, err := parseCustomFormat(tt.spec)
+			require.EqualError(t, err, tt.expected)
+		})
+	}
+}
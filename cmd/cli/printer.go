@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/sushichan044/mdfm"
+)
+
+// Names of the output formats of the query command.
+const (
+	outputNDJSON = "ndjson"
+	outputJSON   = "json"
+	outputYAML   = "yaml"
+	outputCSV    = "csv"
+	outputTSV    = "tsv"
+	outputTable  = "table"
+)
+
+// maxCellLength is the number of runes after which values are truncated in tables.
+const maxCellLength = 60
+
+type (
+	// printer writes the documents printed by the query command in an output format.
+	printer interface {
+		// Print writes a document, as returned by queryCmd.document.
+		Print(doc any) error
+
+		// Close writes the end of the output, after the last document.
+		Close() error
+	}
+
+	// ndjsonPrinter writes each document as JSON on its own line.
+	ndjsonPrinter struct {
+		enc *json.Encoder
+	}
+
+	// jsonArrayPrinter writes the documents as an indented JSON array.
+	jsonArrayPrinter struct {
+		w          io.Writer
+		escapeHTML bool
+		count      int
+	}
+
+	// yamlPrinter writes the documents as a stream of YAML documents.
+	yamlPrinter struct {
+		w io.Writer
+	}
+
+	// csvPrinter writes the selected fields of the documents as CSV or TSV records, after a header.
+	csvPrinter struct {
+		w       *csv.Writer
+		columns []string
+	}
+
+	// tablePrinter writes the selected fields of the documents as an aligned table.
+	// Values are truncated and the table is only written on Close, once the widths are known.
+	tablePrinter struct {
+		w       *tabwriter.Writer
+		columns []string
+	}
+)
+
+// newPrinter returns a printer for the output format. columns are the fields selected with
+// --fields, which tabular formats need since they cannot print whole documents.
+// With escapeHTML, "<", ">" and "&" are escaped in JSON strings.
+func newPrinter(w io.Writer, format string, columns []string, escapeHTML bool) (printer, error) {
+	switch format {
+	case outputJSON:
+		return &jsonArrayPrinter{w: w, escapeHTML: escapeHTML}, nil
+	case outputYAML:
+		return &yamlPrinter{w: w}, nil
+	case outputCSV, outputTSV, outputTable:
+		if columns == nil {
+			return nil, fmt.Errorf("--format %s needs --fields to select the columns", format)
+		}
+		return newTabularPrinter(w, format, columns)
+	case outputNDJSON:
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(escapeHTML)
+		return &ndjsonPrinter{enc: enc}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// newTabularPrinter returns a printer for the csv, tsv or table format, after writing the header.
+func newTabularPrinter(w io.Writer, format string, columns []string) (printer, error) {
+	if format == outputTable {
+		p := &tablePrinter{w: tabwriter.NewWriter(w, 0, 0, 2, ' ', 0), columns: columns}
+		header := make([]string, len(columns))
+		for i, column := range columns {
+			header[i] = strings.ToUpper(column)
+		}
+		return p, p.writeRow(header)
+	}
+
+	p := &csvPrinter{w: csv.NewWriter(w), columns: columns}
+	if format == outputTSV {
+		p.w.Comma = '\t'
+	}
+	return p, p.w.Write(columns)
+}
+
+func (p *ndjsonPrinter) Print(doc any) error {
+	return p.enc.Encode(doc)
+}
+
+func (p *ndjsonPrinter) Close() error {
+	return nil
+}
+
+func (p *jsonArrayPrinter) Print(doc any) error {
+	data, err := encodeJSON(doc, p.escapeHTML, "  ")
+	if err != nil {
+		return err
+	}
+
+	sep := ",\n  "
+	if p.count == 0 {
+		sep = "[\n  "
+	}
+	p.count++
+	_, err = io.WriteString(p.w, sep+strings.TrimSuffix(string(data), "\n"))
+	return err
+}
+
+func (p *jsonArrayPrinter) Close() error {
+	end := "\n]\n"
+	if p.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(p.w, end)
+	return err
+}
+
+func (p *yamlPrinter) Print(doc any) error {
+	// Documents are converted through JSON so that they have the same keys and values in both formats.
+	data, err := encodeJSON(doc, false, "")
+	if err != nil {
+		return err
+	}
+	var om mdfm.OrderedMap
+	if err = json.Unmarshal(data, &om); err != nil {
+		return err
+	}
+
+	out, err := yaml.Marshal(yamlValue(&om))
+	if err != nil {
+		return err
+	}
+	_, err = p.w.Write(append([]byte("---\n"), out...))
+	return err
+}
+
+func (p *yamlPrinter) Close() error {
+	return nil
+}
+
+func (p *csvPrinter) Print(doc any) error {
+	record := make([]string, len(p.columns))
+	for i, column := range p.columns {
+		record[i] = formatCell(column, doc)
+	}
+	if err := p.w.Write(record); err != nil {
+		return err
+	}
+	p.w.Flush()
+	return p.w.Error()
+}
+
+func (p *csvPrinter) Close() error {
+	p.w.Flush()
+	return p.w.Error()
+}
+
+func (p *tablePrinter) Print(doc any) error {
+	cells := make([]string, len(p.columns))
+	for i, column := range p.columns {
+		cell := strings.Join(strings.Fields(formatCell(column, doc)), " ")
+		if utf8.RuneCountInString(cell) > maxCellLength {
+			cell = string([]rune(cell)[:maxCellLength-1]) + "…"
+		}
+		cells[i] = cell
+	}
+	return p.writeRow(cells)
+}
+
+func (p *tablePrinter) Close() error {
+	return p.w.Flush()
+}
+
+func (p *tablePrinter) writeRow(cells []string) error {
+	_, err := fmt.Fprintln(p.w, strings.Join(cells, "\t"))
+	return err
+}
+
+// encodeJSON encodes v as JSON, indented if indent is not empty. Nested lines are prefixed
+// with indent, so that the result can be nested in a JSON array.
+func encodeJSON(v any, escapeHTML bool, indent string) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(escapeHTML)
+	enc.SetIndent(indent, indent)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// formatCell formats the field named column of doc, as returned by project, for tabular formats:
+// strings are written as is, null as an empty cell and other values as JSON.
+func formatCell(column string, doc any) string {
+	om, ok := doc.(*mdfm.OrderedMap)
+	if !ok {
+		return ""
+	}
+
+	value, _ := om.Get(column)
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	}
+	data, err := encodeJSON(value, false, "")
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return strings.TrimSuffix(string(data), "\n")
+}
+
+// yamlValue converts the ordered maps of v into yaml.MapSlice, so that their keys are written in order.
+func yamlValue(v any) any {
+	switch v := v.(type) {
+	case *mdfm.OrderedMap:
+		ms := make(yaml.MapSlice, 0, v.Len())
+		for key, value := range v.All() {
+			ms = append(ms, yaml.MapItem{Key: key, Value: yamlValue(value)})
+		}
+		return ms
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = yamlValue(item)
+		}
+		return items
+	default:
+		return v
+	}
+}
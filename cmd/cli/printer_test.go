@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/mdfm"
+)
+
+// testDocuments are the documents the printer tests print, by path.
+//
+//nolint:gochecknoglobals // Read-only fixtures.
+var testDocuments = []struct {
+	path, content string
+}{
+	{
+		path: "a.md",
+		content: "---\ntitle: Hello, \"World\" <3\ntags: [go, web]\nseo:\n  desc: |\n    line one\n    line two\n" +
+			"summary: " + strings.Repeat("abcdefghij", 7) + "\npath: fm\n---\nBody\n",
+	},
+	{
+		path:    "b.md",
+		content: "Just text\n",
+	},
+}
+
+// parseTestDocuments parses testDocuments like the query command does.
+func parseTestDocuments(t *testing.T) []*mdfm.MarkdownDocument[mdfm.OrderedMap] {
+	t.Helper()
+
+	docs := make([]*mdfm.MarkdownDocument[mdfm.OrderedMap], len(testDocuments))
+	for i, tt := range testDocuments {
+		doc, err := mdfm.ParseBytes[mdfm.OrderedMap]([]byte(tt.content))
+		require.NoError(t, err)
+		docs[i] = doc
+	}
+	return docs
+}
+
+func TestNewPrinter(t *testing.T) {
+	docs := parseTestDocuments(t)
+
+	tests := []struct {
+		name       string
+		format     string
+		columns    []string
+		escapeHTML bool
+		count      int
+		expected   string
+	}{
+		{
+			name:    "json",
+			format:  outputJSON,
+			columns: []string{"path", "title", "tags"},
+			count:   2,
+			expected: `[
+  {
+    "path": "a.md",
+    "title": "Hello, \"World\" <3",
+    "tags": [
+      "go",
+      "web"
+    ]
+  },
+  {
+    "path": "b.md",
+    "title": null,
+    "tags": null
+  }
+]
+`,
+		},
+		{
+			name:     "json without documents",
+			format:   outputJSON,
+			columns:  []string{"path"},
+			expected: "[]\n",
+		},
+		{
+			name:       "json escaping HTML",
+			format:     outputJSON,
+			columns:    []string{"title"},
+			escapeHTML: true,
+			count:      1,
+			expected:   "[\n  {\n    \"title\": \"Hello, \\\"World\\\" \\u003c3\"\n  }\n]\n",
+		},
+		{
+			name:    "ndjson",
+			format:  outputNDJSON,
+			columns: []string{"path", "title", "tags"},
+			count:   2,
+			expected: `{"path":"a.md","title":"Hello, \"World\" <3","tags":["go","web"]}
+{"path":"b.md","title":null,"tags":null}
+`,
+		},
+		{
+			name:    "yaml",
+			format:  outputYAML,
+			columns: []string{"path", "title", "seo"},
+			count:   2,
+			expected: `---
+path: a.md
+title: Hello, "World" <3
+seo:
+  desc: |
+    line one
+    line two
+---
+path: b.md
+title: null
+seo: null
+`,
+		},
+		{
+			name:    "csv",
+			format:  outputCSV,
+			columns: []string{"path", "title", "tags"},
+			count:   2,
+			expected: `path,title,tags
+a.md,"Hello, ""World"" <3","[""go"",""web""]"
+b.md,,
+`,
+		},
+		{
+			name:    "tsv",
+			format:  outputTSV,
+			columns: []string{"path", "seo.desc", "tags"},
+			count:   2,
+			expected: "path\tseo.desc\ttags\n" +
+				"a.md\t\"line one\nline two\n\"\t\"[\"\"go\"\",\"\"web\"\"]\"\n" +
+				"b.md\t\t\n",
+		},
+		{
+			name:    "table",
+			format:  outputTable,
+			columns: []string{"path", "seo.desc", "summary"},
+			count:   2,
+			expected: "PATH  SEO.DESC           SUMMARY\n" +
+				"a.md  line one line two  " + strings.Repeat("abcdefghij", 5) + "abcdefghi…\n" +
+				"b.md                     \n",
+		},
+		{
+			name:     "table without documents",
+			format:   outputTable,
+			columns:  []string{"path", "title"},
+			expected: "PATH  TITLE\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			p, err := newPrinter(&buf, tt.format, tt.columns, tt.escapeHTML)
+			require.NoError(t, err)
+
+			for i, doc := range docs[:tt.count] {
+				require.NoError(t, p.Print(project(testDocuments[i].path, doc, tt.columns)))
+			}
+			require.NoError(t, p.Close())
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+
+	t.Run("whole documents", func(t *testing.T) {
+		var buf bytes.Buffer
+		p, err := newPrinter(&buf, outputNDJSON, nil, false)
+		require.NoError(t, err)
+
+		cmd := &queryCmd{}
+		require.NoError(t, p.Print(cmd.document("b.md", docs[1])))
+		require.NoError(t, p.Close())
+		assert.JSONEq(t, `{"path": "b.md", "body": "Just text\n", "frontMatter": null}`, buf.String())
+	})
+
+	t.Run("tabular formats need columns", func(t *testing.T) {
+		for _, format := range []string{outputCSV, outputTSV, outputTable} {
+			_, err := newPrinter(&bytes.Buffer{}, format, nil, false)
+			require.EqualError(t, err, "--format "+format+" needs --fields to select the columns")
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := newPrinter(&bytes.Buffer{}, "xml", nil, false)
+		require.EqualError(t, err, `unknown output format "xml"`)
+	})
+}
+
+func TestFieldValue(t *testing.T) {
+	docs := parseTestDocuments(t)
+
+	tests := []struct {
+		name     string
+		doc      int
+		field    string
+		expected any
+	}{
+		{name: "path", field: "path", expected: "a.md"},
+		{name: "body", field: "body", expected: "Body\n"},
+		{name: "body start line", field: "bodyStartLine", expected: 11},
+		{name: "format", field: "format", expected: "yaml"},
+		{name: "frontmatter key", field: "title", expected: `Hello, "World" <3`},
+		{name: "nested key", field: "seo.desc", expected: "line one\nline two\n"},
+		{name: "list", field: "tags", expected: []any{"go", "web"}},
+		{name: "key named like a field", field: "frontMatter.path", expected: "fm"},
+		{name: "missing key", field: "draft", expected: nil},
+		{name: "without frontmatter: path", doc: 1, field: "path", expected: "b.md"},
+		{name: "without frontmatter: body", doc: 1, field: "body", expected: "Just text\n"},
+		{name: "without frontmatter: format", doc: 1, field: "format", expected: nil},
+		{name: "without frontmatter: key", doc: 1, field: "title", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, fieldValue(testDocuments[tt.doc].path, docs[tt.doc], tt.field))
+		})
+	}
+
+	t.Run("project keeps the order of the fields", func(t *testing.T) {
+		om := project("a.md", docs[0], []string{"title", "path", "draft"})
+		assert.Equal(t, []string{"title", "path", "draft"}, om.Keys())
+	})
+}
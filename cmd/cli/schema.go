@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"github.com/sushichan044/mdfm"
+	"github.com/sushichan044/mdfm/internal/infer"
+)
+
+// maxExampleLength is the number of runes after which examples are truncated in tables.
+const maxExampleLength = 40
+
+type schemaCmd struct {
+	Patterns []string `arg:"" name:"pattern" help:"Glob patterns to match (eg. '**/*.md'). Prefix with '!' to exclude matches (eg. '!**/drafts/**')."`
+
+	Output string `name:"output" short:"o" enum:"table,json-schema" default:"table" help:"Output format: table or json-schema."`
+
+	formatFlags `embed:""`
+}
+
+func (cmd *schemaCmd) Run() error {
+	schema, hasErrors, err := inferSchema(cmd.Patterns, cmd.formatFlags)
+	if err != nil {
+		return err
+	}
+
+	wtr := bufio.NewWriter(os.Stdout)
+	if cmd.Output == "json-schema" {
+		err = writeJSON(wtr, schema.JSONSchema())
+	} else {
+		err = writeSchemaTable(wtr, schema)
+	}
+	if err == nil {
+		err = wtr.Flush()
+	}
+	if err != nil && !errors.Is(err, syscall.EPIPE) {
+		return fmt.Errorf("error writing output: %w", err)
+	}
+
+	if hasErrors {
+		return errors.New("errors occurred during processing markdown files")
+	}
+	return nil
+}
+
+// inferSchema infers the schema of the frontmatter of the files matched by patterns.
+// Files without frontmatter are skipped, and files that cannot be parsed are reported on
+// standard error, which is reflected by the returned boolean.
+func inferSchema(patterns []string, flags formatFlags) (*infer.Schema, bool, error) {
+	opts, err := flags.options()
+	if err != nil {
+		return nil, false, err
+	}
+	opts = append(opts, mdfm.WithPatterns(patterns[1:]...))
+
+	results, err := mdfm.Glob[mdfm.OrderedMap](patterns[0], opts...)
+	if err != nil {
+		return nil, false, fmt.Errorf("error during glob %s: %w", strings.Join(patterns, " "), err)
+	}
+
+	schema := infer.New()
+	var hasErrors bool
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			hasErrors = true
+			printProcessingError(os.Stderr, result.Path, result.Err)
+		case result.Document.Format != "":
+			schema.Add(&result.Document.FrontMatter)
+		}
+	}
+	return schema, hasErrors, nil
+}
+
+// writeSchemaTable prints one row per key with its kinds, the number of files that have it
+// and example values, followed by the keys whose kinds conflict.
+func writeSchemaTable(w io.Writer, schema *infer.Schema) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tTYPES\tFILES\tEXAMPLES")
+
+	var conflicts []*infer.Field
+	for _, field := range schema.Fields() {
+		kinds := field.SortedKinds()
+		types := make([]string, len(kinds))
+		for i, kind := range kinds {
+			types[i] = string(kind)
+			if len(kinds) > 1 {
+				types[i] += " (" + strconv.Itoa(field.Kinds[kind]) + ")"
+			}
+		}
+		if field.Conflict() {
+			conflicts = append(conflicts, field)
+		}
+
+		examples := make([]string, len(field.Examples))
+		for i, example := range field.Examples {
+			examples[i] = formatExample(example)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d/%d\t%s\n",
+			field.Path, strings.Join(types, ", "), field.Files, schema.Documents(), strings.Join(examples, ", "))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(conflicts) > 0 {
+		fmt.Fprintln(w, "\nConflicting types:")
+		for _, field := range conflicts {
+			var parts []string
+			for _, kind := range field.SortedKinds() {
+				parts = append(parts, fmt.Sprintf("%s in %d files", kind, field.Kinds[kind]))
+			}
+			fmt.Fprintf(w, "  %s: %s\n", field.Path, strings.Join(parts, ", "))
+		}
+	}
+	return nil
+}
+
+// formatExample formats an example value as JSON, truncating long values.
+func formatExample(v any) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+
+	s := strings.TrimSuffix(buf.String(), "\n")
+	if utf8.RuneCountInString(s) > maxExampleLength {
+		s = string([]rune(s)[:maxExampleLength-1]) + "…"
+	}
+	return s
+}
+
+// writeJSON writes v as indented JSON without escaping HTML characters.
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/sushichan044/mdfm"
+)
+
+type validateCmd struct {
+	Patterns []string `arg:"" name:"pattern" help:"Glob patterns to match (eg. '**/*.md'). Prefix with '!' to exclude matches (eg. '!**/drafts/**')."`
+
+	Schema string `name:"schema" required:"" type:"existingfile" help:"JSON Schema file (draft 2020-12) the frontmatter must match."`
+
+	RequireFrontMatter bool `name:"require-frontmatter" help:"Fail files that have no frontmatter block instead of validating them as an empty object."`
+
+	formatFlags `embed:""`
+}
+
+func (cmd *validateCmd) Run() error {
+	schema, err := mdfm.CompileSchema(cmd.Schema)
+	if err != nil {
+		return err
+	}
+
+	opts, err := cmd.options()
+	if err != nil {
+		return err
+	}
+	opts = append(opts, mdfm.WithSchema(schema), mdfm.WithPatterns(cmd.Patterns[1:]...))
+	if cmd.RequireFrontMatter {
+		opts = append(opts, mdfm.WithRequireFrontMatter())
+	}
+
+	results, err := mdfm.GlobStream[mdfm.OrderedMap](cmd.Patterns[0], opts...)
+	if err != nil {
+		return fmt.Errorf("error during glob %s: %w", strings.Join(cmd.Patterns, " "), err)
+	}
+	return reportValidation(os.Stdout, os.Stderr, results)
+}
+
+// reportValidation prints the schema violations of every result on w, one per line as
+// "path:line:column: key: message", and other errors and a summary on errW.
+func reportValidation(w, errW io.Writer, results <-chan mdfm.Result[mdfm.OrderedMap]) error {
+	wtr := bufio.NewWriter(w)
+
+	var total, failed int
+	for result := range results {
+		total++
+		if result.Err == nil {
+			continue
+		}
+		failed++
+
+		var validationErr *mdfm.ValidationError
+		if !errors.As(result.Err, &validationErr) {
+			printProcessingError(errW, result.Path, result.Err)
+			continue
+		}
+		for _, v := range validationErr.Violations {
+			fmt.Fprintln(wtr, formatLocation(result.Path, v.Line, v.Column)+": "+v.String())
+		}
+		// Keep the violations next to the errors of other files when both go to a terminal.
+		if err := wtr.Flush(); err != nil {
+			if errors.Is(err, syscall.EPIPE) {
+				return nil
+			}
+			return fmt.Errorf("error flushing output: %w", err)
+		}
+	}
+
+	fmt.Fprintf(errW, "%d of %d files valid\n", total-failed, total)
+	if failed > 0 {
+		return errors.New("some files failed validation")
+	}
+	return nil
+}
+
+// formatLocation formats a position in a file as "path:line:column", omitting the unknown parts.
+func formatLocation(path string, line, column int) string {
+	switch {
+	case line == 0:
+		return path
+	case column == 0:
+		return fmt.Sprintf("%s:%d", path, line)
+	default:
+		return fmt.Sprintf("%s:%d:%d", path, line, column)
+	}
+}
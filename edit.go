@@ -0,0 +1,231 @@
+package mdfm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/sushichan044/mdfm/internal/tree"
+)
+
+// Change is the outcome of editing a single Markdown file with Edit or RenameKey.
+type Change struct {
+	// Path is the path of the file, as reported by Glob.
+	Path string
+
+	// Before is the content of the file before the edit, and After its content after the edit.
+	// After equals Before if the edit changed nothing. Both are nil if the file could not be
+	// read or parsed.
+	Before []byte
+	After  []byte
+
+	// Err is the error that occurred while reading, parsing, editing or writing the file, if any.
+	Err error
+}
+
+// Changed reports whether the file was modified, or would be with WithDryRun.
+func (c Change) Changed() bool {
+	return c.Err == nil && !bytes.Equal(c.Before, c.After)
+}
+
+// Edit finds Markdown files like Glob, lets fn modify the frontmatter of each of them and writes
+// back the files that changed, one after another. fn receives the frontmatter with its keys in
+// source order; it is empty for files without frontmatter, which get a YAML block if keys are added.
+//
+// Files are rewritten like WriteFile does: the body, the format and, as much as possible, the
+// comments and layout of the frontmatter are kept. Use WithDryRun to only compute the changes.
+//
+// Example usage:
+//
+//	changes, err := Edit("docs/**/*.md", func(fm *OrderedMap) error {
+//		fm.Set("draft", false)
+//		return nil
+//	})
+//
+// Like Glob, the function returns an error only for fatal conditions; per-file errors,
+// including the ones returned by fn, are reported in Change.Err and leave the file untouched.
+// Edit cannot be used with WithFS, as fs.FS is read-only, nor with WithoutBody, as the body is written back.
+func Edit(glob string, fn func(fm *OrderedMap) error, opts ...Option) ([]Change, error) {
+	o, err := newOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	if o.fsys != nil {
+		return nil, errors.New("mdfm: Edit cannot write files read with WithFS")
+	}
+	if o.skipBody {
+		return nil, errors.New("mdfm: Edit cannot write files read with WithoutBody")
+	}
+
+	results, err := Glob[OrderedMap](glob, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]Change, len(results))
+	for i, result := range results {
+		changes[i] = editResult(result, o, fn)
+	}
+	return changes, nil
+}
+
+// RenameKey renames the frontmatter key from to to in every file matched by glob, using Edit.
+// Nested keys are joined with "." as in "seo.desc"; the key keeps its position if it stays in
+// the same mapping. Files without the key are left untouched.
+//
+// If a file already has the key to, it is reported with ErrKeyExists unless WithMergeValues
+// is given. Use WithDryRun to only compute the changes.
+func RenameKey(glob, from, to string, opts ...Option) ([]Change, error) {
+	if from == "" || to == "" {
+		return nil, errors.New("mdfm: RenameKey requires non-empty keys")
+	}
+	if strings.HasPrefix(to+".", from+".") {
+		return nil, fmt.Errorf("mdfm: cannot rename %s to %s", from, to)
+	}
+
+	o, err := newOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return Edit(glob, func(fm *OrderedMap) error {
+		return renameKey(fm, from, to, o.mergeValues)
+	}, opts...)
+}
+
+func editResult(result Result[OrderedMap], o *options, fn func(fm *OrderedMap) error) Change {
+	change := Change{Path: result.Path, Err: result.Err}
+	if result.Err != nil {
+		return change
+	}
+
+	path := filepath.Join(o.root, filepath.FromSlash(result.Path))
+	before, err := os.ReadFile(path)
+	if err != nil {
+		change.Err = wrapIOError(err)
+		return change
+	}
+
+	doc := result.Document
+	if fnErr := fn(&doc.FrontMatter); fnErr != nil {
+		change.Err = fnErr
+		return change
+	}
+	after, err := Marshal(doc)
+	if err != nil {
+		change.Err = err
+		return change
+	}
+
+	change.Before, change.After = before, after
+	if change.Changed() && !o.dryRun {
+		change.Err = writeFileAtomic(path, after)
+	}
+	return change
+}
+
+func renameKey(fm *OrderedMap, from, to string, merge bool) error {
+	value, found := fm.GetPath(from)
+	if !found {
+		return nil
+	}
+
+	if existing, exists := fm.GetPath(to); exists {
+		if !merge {
+			return fmt.Errorf("cannot rename %s to %s: %w", from, to, ErrKeyExists)
+		}
+		merged, ok := mergeValues(existing, value)
+		if !ok {
+			return fmt.Errorf("cannot merge %s into %s, their values are incompatible: %w", from, to, ErrKeyExists)
+		}
+		fm.DeletePath(from)
+		return fm.SetPath(to, merged)
+	}
+
+	fromParent, fromKey := splitPath(from)
+	toParent, toKey := splitPath(to)
+	if fromParent == toParent {
+		parent := fm
+		if fromParent != "" {
+			v, _ := fm.GetPath(fromParent)
+			parent = v.(*OrderedMap) //nolint:forcetypeassert // It holds from.
+		}
+		parent.Rename(fromKey, toKey)
+		return nil
+	}
+
+	if err := fm.SetPath(to, value); err != nil {
+		return err
+	}
+	fm.DeletePath(from)
+
+	// Drop the mappings that only held the key, e.g. "seo" when moving "seo.desc" out.
+	for parent := fromParent; parent != ""; parent, _ = splitPath(parent) {
+		if v, _ := fm.GetPath(parent); v.(*OrderedMap).Len() > 0 { //nolint:forcetypeassert // It held from.
+			break
+		}
+		fm.DeletePath(parent)
+	}
+	return nil
+}
+
+// mergeValues combines the value of a renamed key into the value of the existing key.
+// It reports false if the values are incompatible.
+func mergeValues(dst, src any) (any, bool) {
+	if tree.Equal(dst, src) {
+		return dst, true
+	}
+
+	switch d := dst.(type) {
+	case *OrderedMap:
+		s, ok := src.(*OrderedMap)
+		if !ok {
+			return nil, false
+		}
+		merged := NewOrderedMap()
+		for key, value := range d.All() {
+			merged.Set(key, value)
+		}
+		for key, value := range s.All() {
+			if existing, found := merged.Get(key); found {
+				if value, ok = mergeValues(existing, value); !ok {
+					return nil, false
+				}
+			}
+			merged.Set(key, value)
+		}
+		return merged, true
+	case []any:
+		items, ok := src.([]any)
+		if !ok {
+			if _, isMap := src.(*OrderedMap); isMap {
+				return nil, false
+			}
+			items = []any{src}
+		}
+		merged := slices.Clone(d)
+		for _, item := range items {
+			if !slices.ContainsFunc(merged, func(v any) bool { return tree.Equal(v, item) }) {
+				merged = append(merged, item)
+			}
+		}
+		return merged, true
+	default:
+		if items, ok := src.([]any); ok {
+			return mergeValues([]any{dst}, items)
+		}
+		return nil, false
+	}
+}
+
+// splitPath splits a key path into the path of its parent mapping and its last key.
+func splitPath(path string) (string, string) {
+	i := strings.LastIndexByte(path, '.')
+	if i < 0 {
+		return "", path
+	}
+	return path[:i], path[i+1:]
+}
@@ -0,0 +1,167 @@
+package mdfm_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/mdfm"
+)
+
+func setupEditFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	return dir
+}
+
+func TestEdit(t *testing.T) {
+	files := map[string]string{
+		"a.md": "---\ntitle: A # keep\ndraft: true\n---\nA\n",
+		"b.md": "+++\ntitle = \"B\"\ndraft = false\n+++\nB\n",
+		"c.md": "No frontmatter\n",
+	}
+	setDraft := func(fm *mdfm.OrderedMap) error {
+		fm.Set("draft", false)
+		return nil
+	}
+
+	t.Run("writes changed files", func(t *testing.T) {
+		dir := setupEditFiles(t, files)
+
+		changes, err := mdfm.Edit("*.md", setDraft, mdfm.WithRoot(dir))
+		require.NoError(t, err)
+		require.Len(t, changes, 3)
+
+		changed := map[string]bool{}
+		for _, change := range changes {
+			require.NoError(t, change.Err)
+			changed[change.Path] = change.Changed()
+		}
+		assert.Equal(t, map[string]bool{"a.md": true, "b.md": false, "c.md": true}, changed)
+
+		assert.Equal(t, "---\ntitle: A # keep\ndraft: false\n---\nA\n", readTestFile(t, filepath.Join(dir, "a.md")))
+		assert.Equal(t, files["b.md"], readTestFile(t, filepath.Join(dir, "b.md")))
+		assert.Equal(t, "---\ndraft: false\n---\nNo frontmatter\n", readTestFile(t, filepath.Join(dir, "c.md")))
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		dir := setupEditFiles(t, files)
+
+		changes, err := mdfm.Edit("a.md", setDraft, mdfm.WithRoot(dir), mdfm.WithDryRun())
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+
+		assert.True(t, changes[0].Changed())
+		assert.Equal(t, files["a.md"], string(changes[0].Before))
+		assert.Equal(t, "---\ntitle: A # keep\ndraft: false\n---\nA\n", string(changes[0].After))
+		assert.Equal(t, files["a.md"], readTestFile(t, filepath.Join(dir, "a.md")))
+	})
+
+	t.Run("errors are reported per file", func(t *testing.T) {
+		dir := setupEditFiles(t, files)
+		errStop := errors.New("stop")
+
+		changes, err := mdfm.Edit("*.md", func(fm *mdfm.OrderedMap) error {
+			if _, ok := fm.Get("title"); ok {
+				return errStop
+			}
+			return nil
+		}, mdfm.WithRoot(dir))
+		require.NoError(t, err)
+
+		for _, change := range changes {
+			if change.Path == "c.md" {
+				assert.NoError(t, change.Err)
+				continue
+			}
+			require.ErrorIs(t, change.Err, errStop)
+			assert.False(t, change.Changed())
+		}
+		assert.Equal(t, files["a.md"], readTestFile(t, filepath.Join(dir, "a.md")))
+	})
+
+	t.Run("read-only file system", func(t *testing.T) {
+		_, err := mdfm.Edit("*.md", setDraft, mdfm.WithFS(fstest.MapFS{}))
+		require.Error(t, err)
+	})
+
+	t.Run("without body", func(t *testing.T) {
+		dir := setupEditFiles(t, files)
+
+		_, err := mdfm.Edit("*.md", setDraft, mdfm.WithRoot(dir), mdfm.WithoutBody())
+		require.EqualError(t, err, "mdfm: Edit cannot write files read with WithoutBody")
+		_, err = mdfm.RenameKey("*.md", "title", "name", mdfm.WithRoot(dir), mdfm.WithoutBody())
+		require.Error(t, err)
+		assert.Equal(t, files["a.md"], readTestFile(t, filepath.Join(dir, "a.md")))
+	})
+}
+
+func TestRenameKey(t *testing.T) {
+	files := map[string]string{
+		"a.md": "---\ntitle: A\ncategories: [go, web] # topics\nseo:\n  desc: hello\n---\nA\n",
+		"b.md": "+++\ncategories = [\"go\"]\ntags = [\"go\", \"cli\"]\n+++\nB\n",
+		"c.md": "---\ntitle: C\n---\nC\n",
+	}
+
+	t.Run("keeps position and style", func(t *testing.T) {
+		dir := setupEditFiles(t, files)
+
+		changes, err := mdfm.RenameKey("*.md", "categories", "tags", mdfm.WithRoot(dir))
+		require.NoError(t, err)
+		require.Len(t, changes, 3)
+
+		assert.Equal(t, "---\ntitle: A\ntags: [go, web] # topics\nseo:\n  desc: hello\n---\nA\n",
+			readTestFile(t, filepath.Join(dir, "a.md")))
+		require.ErrorIs(t, changes[1].Err, mdfm.ErrKeyExists)
+		assert.Equal(t, files["b.md"], readTestFile(t, filepath.Join(dir, "b.md")))
+		require.NoError(t, changes[2].Err)
+		assert.False(t, changes[2].Changed())
+	})
+
+	t.Run("merge values", func(t *testing.T) {
+		dir := setupEditFiles(t, files)
+
+		changes, err := mdfm.RenameKey("b.md", "categories", "tags", mdfm.WithRoot(dir), mdfm.WithMergeValues())
+		require.NoError(t, err)
+		require.NoError(t, changes[0].Err)
+
+		assert.Equal(t, "+++\ntags = [\"go\", \"cli\"]\n+++\nB\n", readTestFile(t, filepath.Join(dir, "b.md")))
+	})
+
+	t.Run("incompatible values", func(t *testing.T) {
+		dir := setupEditFiles(t, map[string]string{"d.md": "---\na: 1\nb: 2\n---\n"})
+
+		changes, err := mdfm.RenameKey("d.md", "a", "b", mdfm.WithRoot(dir), mdfm.WithMergeValues())
+		require.NoError(t, err)
+		require.ErrorIs(t, changes[0].Err, mdfm.ErrKeyExists)
+	})
+
+	t.Run("nested keys", func(t *testing.T) {
+		dir := setupEditFiles(t, files)
+
+		_, err := mdfm.RenameKey("a.md", "seo.desc", "seo.description", mdfm.WithRoot(dir))
+		require.NoError(t, err)
+		assert.Contains(t, readTestFile(t, filepath.Join(dir, "a.md")), "seo:\n  description: hello\n")
+
+		_, err = mdfm.RenameKey("a.md", "seo.description", "description", mdfm.WithRoot(dir))
+		require.NoError(t, err)
+		assert.Equal(t, "---\ntitle: A\ncategories: [go, web] # topics\ndescription: hello\n---\nA\n",
+			readTestFile(t, filepath.Join(dir, "a.md")))
+	})
+
+	t.Run("invalid keys", func(t *testing.T) {
+		_, err := mdfm.RenameKey("*.md", "seo", "seo.description")
+		require.Error(t, err)
+		_, err = mdfm.RenameKey("*.md", "", "title")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,173 @@
+package mdfm
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sushichan044/mdfm/internal/markdown"
+)
+
+var (
+	// ErrNoFrontMatter is reported when a document is required to have a frontmatter block but has none.
+	ErrNoFrontMatter = errors.New("no frontmatter")
+
+	// ErrIO is wrapped by errors that occur while opening, reading or writing a document,
+	// as opposed to errors in its content.
+	ErrIO = errors.New("i/o error")
+
+	// ErrKeyExists is reported by RenameKey when the new key is already present and the
+	// values cannot be combined, see WithMergeValues.
+	ErrKeyExists = errors.New("key already exists")
+)
+
+// ParseError is reported when the frontmatter of a document cannot be decoded.
+// Use errors.As to access the position of the problem:
+//
+//	var parseErr *mdfm.ParseError
+//	if errors.As(result.Err, &parseErr) {
+//		fmt.Printf("%s:%d: %s\n", parseErr.Path, parseErr.Line, parseErr.Snippet)
+//	}
+type ParseError struct {
+	// Path is the path of the document, or empty if it was not read from a file.
+	Path string
+
+	// Format is the name of the frontmatter format, e.g. "yaml", "toml" or "json".
+	Format string
+
+	// Line is the 1-based line number in the document where decoding failed, or 0 if unknown.
+	Line int
+
+	// Column is the 1-based column where decoding failed, or 0 if unknown.
+	Column int
+
+	// Snippet is the content of the line where decoding failed, if known.
+	Snippet string
+
+	// Err is the underlying error returned by the frontmatter decoder.
+	Err error
+
+	reason string
+}
+
+func (e *ParseError) Error() string {
+	return withLocation(e.Path, e.Line, e.Column, fmt.Sprintf("invalid %s frontmatter: %s", e.Format, e.reason))
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// UnknownFieldsError is reported by WithStrictDecoding when the frontmatter of a document
+// has keys that do not match any field of the type it is decoded into.
+type UnknownFieldsError struct {
+	// Path is the path of the document, or empty if it was not read from a file.
+	Path string
+
+	// Format is the name of the frontmatter format, e.g. "yaml", "toml" or "json".
+	Format string
+
+	// Fields are the unknown keys, in the order they appear in the document.
+	Fields []UnknownField
+}
+
+// UnknownField is a frontmatter key that does not match any field.
+type UnknownField struct {
+	// Key is the path of the key, e.g. "seo.descripton" or "authors.0.nmae".
+	Key string
+
+	// Line is the 1-based line number in the document where the key is defined, or 0 if unknown.
+	Line int
+
+	// Column is the 1-based column where the key is defined, or 0 if unknown.
+	Column int
+}
+
+func (e *UnknownFieldsError) Error() string {
+	keys := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		keys[i] = strconv.Quote(f.Key)
+	}
+
+	noun := "field"
+	if len(keys) > 1 {
+		noun = "fields"
+	}
+	msg := fmt.Sprintf("unknown %s frontmatter %s %s", e.Format, noun, strings.Join(keys, ", "))
+
+	var first UnknownField
+	if len(e.Fields) > 0 {
+		first = e.Fields[0]
+	}
+	return withLocation(e.Path, first.Line, first.Column, msg)
+}
+
+func newUnknownFieldsError(path string, block *markdown.Block, keys []string) *UnknownFieldsError {
+	positions := markdown.KeyPositions(block)
+
+	fields := make([]UnknownField, len(keys))
+	for i, key := range keys {
+		pos := positions[key]
+		fields[i] = UnknownField{Key: key, Line: pos.Line, Column: pos.Column}
+	}
+	slices.SortStableFunc(fields, func(a, b UnknownField) int {
+		// Keys without a known position go last.
+		if (a.Line == 0) != (b.Line == 0) {
+			return cmp.Compare(b.Line, a.Line)
+		}
+		return cmp.Or(cmp.Compare(a.Line, b.Line), cmp.Compare(a.Column, b.Column))
+	})
+
+	return &UnknownFieldsError{Path: path, Format: block.Format.Name, Fields: fields}
+}
+
+// withLocation prefixes msg with "path:line:column: ", omitting the unknown parts.
+func withLocation(path string, line, column int, msg string) string {
+	var location []string
+	if path != "" {
+		location = append(location, path)
+	}
+	if line > 0 {
+		location = append(location, strconv.Itoa(line))
+		if column > 0 {
+			location = append(location, strconv.Itoa(column))
+		}
+	}
+
+	if len(location) == 0 {
+		return msg
+	}
+	return strings.Join(location, ":") + ": " + msg
+}
+
+// noFrontMatterError returns ErrNoFrontMatter, prefixed with path if it is known.
+func noFrontMatterError(path string) error {
+	if path == "" {
+		return ErrNoFrontMatter
+	}
+	return fmt.Errorf("%s: %w", path, ErrNoFrontMatter)
+}
+
+// wrapParseError converts errors returned by markdown.Parse into public error types.
+func wrapParseError(path string, err error) error {
+	var decodeErr *markdown.DecodeError
+	if errors.As(err, &decodeErr) {
+		return &ParseError{
+			Path:    path,
+			Format:  decodeErr.Format,
+			Line:    decodeErr.Line,
+			Column:  decodeErr.Column,
+			Snippet: decodeErr.Snippet,
+			Err:     decodeErr.Err,
+			reason:  decodeErr.Reason,
+		}
+	}
+	return wrapIOError(err)
+}
+
+func wrapIOError(err error) error {
+	return fmt.Errorf("%w: %w", ErrIO, err)
+}
@@ -0,0 +1,115 @@
+package mdfm_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/mdfm"
+)
+
+func TestParseError(t *testing.T) {
+	setupTestFiles(t)
+
+	results, err := mdfm.Glob[testMetadata]("invalid-frontmatter.md")
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	var parseErr *mdfm.ParseError
+	require.ErrorAs(t, results[0].Err, &parseErr)
+
+	assert.Equal(t, "invalid-frontmatter.md", parseErr.Path)
+	assert.Equal(t, "yaml", parseErr.Format)
+	assert.Equal(t, 2, parseErr.Line)
+	assert.Equal(t, `title: "Unclosed quote`, parseErr.Snippet)
+	assert.NotNil(t, errors.Unwrap(parseErr))
+	assert.Regexp(t, `^invalid-frontmatter\.md:2: invalid yaml frontmatter: `, parseErr.Error())
+	assert.NotErrorIs(t, results[0].Err, mdfm.ErrIO)
+}
+
+func TestParseError_WithoutPath(t *testing.T) {
+	_, err := mdfm.ParseBytes[testMetadata]([]byte(";;;\n{\"title\": 1}\n;;;\n"))
+
+	var parseErr *mdfm.ParseError
+	require.ErrorAs(t, err, &parseErr)
+
+	assert.Empty(t, parseErr.Path)
+	assert.Equal(t, "json", parseErr.Format)
+	assert.Equal(t, 2, parseErr.Line)
+	assert.Positive(t, parseErr.Column)
+	assert.Regexp(t, `^2:\d+: invalid json frontmatter: `, parseErr.Error())
+}
+
+func TestErrIO(t *testing.T) {
+	setupTestFiles(t)
+
+	_, err := mdfm.ParseFile[testMetadata]("does-not-exist.md")
+	require.ErrorIs(t, err, mdfm.ErrIO)
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	var parseErr *mdfm.ParseError
+	assert.NotErrorAs(t, err, &parseErr)
+}
+
+func TestWithRequireFrontMatter(t *testing.T) {
+	_, err := mdfm.ParseBytes[testMetadata]([]byte("# No frontmatter"), mdfm.WithRequireFrontMatter())
+	require.ErrorIs(t, err, mdfm.ErrNoFrontMatter)
+	assert.EqualError(t, err, "no frontmatter")
+
+	doc, err := mdfm.ParseBytes[testMetadata]([]byte("---\ntitle: Title\n---\nBody"), mdfm.WithRequireFrontMatter())
+	require.NoError(t, err)
+	assert.Equal(t, "Title", doc.FrontMatter.Title)
+
+	_, err = mdfm.ParseBytes[testMetadata]([]byte("# No frontmatter"))
+	require.NoError(t, err)
+}
+
+func TestWithStrictDecoding(t *testing.T) {
+	input := "---\ntitle: Title\ndescripton: typo\nextra:\n  nested: value\n---\nBody"
+
+	_, err := mdfm.ParseBytes[testMetadata]([]byte(input))
+	require.NoError(t, err)
+
+	doc, err := mdfm.ParseBytes[map[string]any]([]byte(input), mdfm.WithStrictDecoding())
+	require.NoError(t, err)
+	assert.Equal(t, "Title", doc.FrontMatter["title"])
+
+	_, err = mdfm.ParseBytes[testMetadata]([]byte(input), mdfm.WithStrictDecoding())
+
+	var unknownErr *mdfm.UnknownFieldsError
+	require.ErrorAs(t, err, &unknownErr)
+	assert.Empty(t, unknownErr.Path)
+	assert.Equal(t, "yaml", unknownErr.Format)
+	assert.Equal(t, []mdfm.UnknownField{
+		{Key: "descripton", Line: 3, Column: 1},
+		{Key: "extra", Line: 4, Column: 1},
+	}, unknownErr.Fields)
+	assert.Equal(t, `3:1: unknown yaml frontmatter fields "descripton", "extra"`, unknownErr.Error())
+}
+
+func TestWithStrictDecoding_Glob(t *testing.T) {
+	fsys := fstest.MapFS{
+		"post.md":  {Data: []byte("+++\ntitle = \"Title\"\nversoin = 1\n+++\nBody")},
+		"plain.md": {Data: []byte("Body")},
+	}
+
+	results, err := mdfm.GlobFS[testMetadata](fsys, "*.md", mdfm.WithStrictDecoding(), mdfm.WithRequireFrontMatter())
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	errs := map[string]error{}
+	for _, result := range results {
+		errs[result.Path] = result.Err
+	}
+
+	require.ErrorIs(t, errs["plain.md"], mdfm.ErrNoFrontMatter)
+	assert.EqualError(t, errs["plain.md"], "plain.md: no frontmatter")
+
+	var unknownErr *mdfm.UnknownFieldsError
+	require.ErrorAs(t, errs["post.md"], &unknownErr)
+	assert.Equal(t, `post.md:3:1: unknown toml frontmatter field "versoin"`, unknownErr.Error())
+}
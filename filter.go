@@ -0,0 +1,123 @@
+package mdfm
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/sushichan044/mdfm/internal/expr"
+	"github.com/sushichan044/mdfm/internal/markdown"
+	"github.com/sushichan044/mdfm/internal/tree"
+)
+
+type (
+	// Filter is a compiled filter expression that selects documents by their frontmatter and
+	// file metadata, see CompileFilter and WithFilter.
+	Filter struct {
+		expr *expr.Expr
+	}
+
+	// FileInfo describes the file of a document to a Filter.
+	FileInfo struct {
+		// Path is the path of the file as reported by Result.Path.
+		Path string
+
+		// Format is the name of the frontmatter format, or empty if the document has no frontmatter.
+		Format string
+
+		// Size is the size of the file in bytes.
+		Size int64
+
+		// ModTime is the modification time of the file.
+		ModTime time.Time
+	}
+)
+
+// filterVars are the variables filter expressions can refer to, see CompileFilter.
+//
+//nolint:gochecknoglobals // Read-only list.
+var filterVars = []string{"path", "name", "dir", "ext", "format", "size", "modTime"}
+
+// errFilteredOut is returned by processMarkdownFile for documents that do not match the filter.
+// Such documents are dropped from the results.
+var errFilteredOut = errors.New("document does not match the filter")
+
+// CompileFilter compiles a filter expression, e.g.
+//
+//	draft == false && "go" in tags && date >= "2024-01-01"
+//
+// Identifiers refer to frontmatter keys, with "." and [] for nested keys and list items
+// (seo.description, tags[0], seo["og:image"]) and backquotes for keys that are not identifiers
+// (`allowed-tools`). Missing keys are null.
+//
+// File metadata is available as $path, $name (the base name), $dir, $ext, $format (the frontmatter
+// format), $size (in bytes) and $modTime.
+//
+// Values are compared with ==, !=, <, <=, > and >=; numbers are compared numerically, dates and
+// datetimes chronologically (including those given as strings, e.g. "2024-01-01") and other
+// strings lexicographically. Values of different types are never equal nor ordered.
+// The other operators are:
+//
+//   - a in b and a not in b: b is a list containing a, a string containing a or a mapping with the key a,
+//   - a =~ "re" and a !~ "re": a is a string (not) matching the regular expression re,
+//   - &&, || and !, where only false and null are falsy,
+//   - len(v), lower(s), upper(s), startsWith(s, prefix), endsWith(s, suffix) and glob(s, pattern).
+//
+// Invalid expressions are reported with the column of the problem.
+func CompileFilter(expression string) (*Filter, error) {
+	e, err := expr.Parse(expression, filterVars)
+	if err != nil {
+		return nil, fmt.Errorf("mdfm: invalid filter: %w", err)
+	}
+	return &Filter{expr: e}, nil
+}
+
+// Match reports whether a document matches the filter. frontMatter is the decoded frontmatter,
+// e.g. the FrontMatter of a MarkdownDocument[OrderedMap] or MarkdownDocument[map[string]any],
+// or a value returned by NormalizeJSON. Structs are not supported.
+func (f *Filter) Match(frontMatter any, file FileInfo) bool {
+	return f.expr.Match(expr.Env{
+		FrontMatter: tree.Normalize(frontMatter),
+		Vars:        file.vars(),
+	})
+}
+
+// vars returns the values of the variables of filter expressions.
+func (file FileInfo) vars() map[string]any {
+	slashed := filepath.ToSlash(file.Path)
+	vars := map[string]any{
+		"path":   slashed,
+		"name":   path.Base(slashed),
+		"dir":    path.Dir(slashed),
+		"ext":    path.Ext(slashed),
+		"format": file.Format,
+		"size":   file.Size,
+	}
+	if !file.ModTime.IsZero() {
+		vars["modTime"] = file.ModTime
+	}
+	return vars
+}
+
+// matchFile reports whether the document parsed from the matched file at p matches the filter.
+// block is the frontmatter block of the document, or nil if it has none.
+func (f *Filter) matchFile(src *source, p string, block *markdown.Block) (bool, error) {
+	file := FileInfo{Path: p}
+
+	info, err := src.stat(p)
+	if err != nil {
+		return false, wrapIOError(err)
+	}
+	file.Size, file.ModTime = info.Size(), info.ModTime()
+
+	var frontMatter any = map[string]any{}
+	if block != nil {
+		file.Format = block.Format.Name
+		if frontMatter, err = markdown.Generic(block); err != nil {
+			return false, wrapParseError(p, err)
+		}
+	}
+	return f.expr.Match(expr.Env{FrontMatter: frontMatter, Vars: file.vars()}), nil
+}
@@ -0,0 +1,117 @@
+package mdfm_test
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/mdfm"
+)
+
+func compileTestFilter(t *testing.T, expression string) *mdfm.Filter {
+	t.Helper()
+
+	filter, err := mdfm.CompileFilter(expression)
+	require.NoError(t, err)
+	return filter
+}
+
+func TestWithFilter(t *testing.T) {
+	setupTestFiles(t)
+
+	// invalid-frontmatter.md is always reported, since it cannot be parsed.
+	tests := []struct {
+		name  string
+		where string
+		want  []string
+	}{
+		{
+			name:  "frontmatter values",
+			where: `published == true && "golang" in tags`,
+			want:  []string{"blog/post1.md", "invalid-frontmatter.md"},
+		},
+		{
+			name:  "missing keys are null",
+			where: `tags == null && published == false`,
+			want:  []string{"blog/draft.md", "invalid-frontmatter.md"},
+		},
+		{
+			name:  "file metadata",
+			where: `$dir == "blog" && $name != "draft.md" && $format == "yaml" && $size > 0`,
+			want:  []string{"blog/post1.md", "blog/post2.md", "invalid-frontmatter.md"},
+		},
+		{
+			name:  "documents without frontmatter",
+			where: `$format == "" && $ext == ".md"`,
+			want:  []string{"no-frontmatter.md", "empty.md", "invalid-frontmatter.md"},
+		},
+		{
+			name:  "files that cannot be parsed are still reported",
+			where: `false`,
+			want:  []string{"invalid-frontmatter.md"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := mdfm.Glob[testMetadata]("**/*.md", mdfm.WithFilter(compileTestFilter(t, tt.where)))
+			require.NoError(t, err)
+
+			var paths []string
+			for _, result := range results {
+				paths = append(paths, result.Path)
+			}
+			assert.ElementsMatch(t, tt.want, paths)
+		})
+	}
+}
+
+func TestWithFilter_Stream(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.md": {Data: []byte("---\ndate: 2024-03-01\n---\n"), ModTime: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		"b.md": {Data: []byte("+++\ndate = 2023-12-31\n+++\n"), ModTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	tests := []struct {
+		where string
+		want  []string
+	}{
+		{`date >= "2024-01-01"`, []string{"a.md"}},
+		{`date <= "2023-12-31"`, []string{"b.md"}},
+		{`$modTime >= "2024-06-01"`, []string{"b.md"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.where, func(t *testing.T) {
+			resultChan, err := mdfm.GlobStream[mdfm.OrderedMap]("*.md", mdfm.WithFS(fsys), mdfm.WithFilter(compileTestFilter(t, tt.where)))
+			require.NoError(t, err)
+
+			var paths []string
+			for result := range resultChan {
+				require.NoError(t, result.Err)
+				paths = append(paths, result.Path)
+			}
+			assert.Equal(t, tt.want, paths)
+		})
+	}
+}
+
+func TestFilter_Match(t *testing.T) {
+	doc, err := mdfm.ParseBytes[mdfm.OrderedMap]([]byte("---\ntitle: Hello\ntags: [go]\n---\n"))
+	require.NoError(t, err)
+
+	filter := compileTestFilter(t, `"go" in tags && startsWith($path, "posts/")`)
+	assert.True(t, filter.Match(doc.FrontMatter, mdfm.FileInfo{Path: "posts/hello.md"}))
+	assert.False(t, filter.Match(doc.FrontMatter, mdfm.FileInfo{Path: "hello.md"}))
+	assert.True(t, filter.Match(map[string]any{"tags": []string{"go"}}, mdfm.FileInfo{Path: "posts/a.md"}))
+	assert.False(t, filter.Match(nil, mdfm.FileInfo{Path: "posts/a.md"}))
+}
+
+func TestCompileFilter(t *testing.T) {
+	_, err := mdfm.CompileFilter(`draft = false`)
+	require.EqualError(t, err, "mdfm: invalid filter: column 7: unexpected character '='")
+
+	_, err = mdfm.CompileFilter(`$title == "a"`)
+	require.EqualError(t, err, "mdfm: invalid filter: column 1: unknown variable $title (want one of $path, $name, $dir, $ext, $format, $size, $modTime)")
+}
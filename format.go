@@ -0,0 +1,117 @@
+package mdfm
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/sushichan044/mdfm/internal/markdown"
+)
+
+// Format describes a custom frontmatter format, identified by the lines that open and close
+// the frontmatter block. The lines between the delimiters are passed to Unmarshal.
+//
+// Example, YAML frontmatter inside an HTML comment:
+//
+//	mdfm.Format{
+//		Name:      mdfm.FormatYAML,
+//		Start:     "<!-- ---",
+//		End:       "--- -->",
+//		Unmarshal: yaml.Unmarshal,
+//		FieldTag:  "yaml",
+//	}
+type Format struct {
+	// Name is reported as MarkdownDocument.Format and ParseError.Format.
+	// Use FormatYAML, FormatTOML or FormatJSON for data in one of these formats to
+	// enable key positions, strict and unified decoding, and OrderedMap key order.
+	Name string
+
+	// Start is the line that opens the frontmatter block.
+	// Leading empty lines are skipped and surrounding white space is ignored.
+	Start string
+
+	// End is the line that closes the frontmatter block.
+	End string
+
+	// Unmarshal decodes the content of the frontmatter block into v, e.g. yaml.Unmarshal.
+	Unmarshal func(data []byte, v any) error
+
+	// FieldTag is the struct tag Unmarshal reads field names from, e.g. "yaml".
+	// It is only used by WithStrictDecoding; leave it empty to skip the check for this format.
+	FieldTag string
+}
+
+//nolint:gochecknoglobals // The registry is global by design, like database/sql drivers.
+var registry struct {
+	sync.RWMutex
+
+	formats []Format
+}
+
+// RegisterFormat makes f available to every Glob and Parse call, in addition to the
+// built-in formats. Formats are tried in this order: formats given with WithFormats,
+// registered formats in the order they were registered, then the built-in formats.
+// The first format whose Start line matches is used, so a custom format can take over
+// the delimiters of a built-in one.
+//
+// RegisterFormat is typically called from an init function. It panics if f is invalid.
+func RegisterFormat(f Format) {
+	if err := f.validate(); err != nil {
+		panic(fmt.Sprintf("mdfm: RegisterFormat: %v", err))
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+	registry.formats = append(registry.formats, f)
+}
+
+// WithFormats adds frontmatter formats for a single call.
+// They take precedence over registered and built-in formats; see RegisterFormat.
+// If a format is invalid, the call it is passed to fails with an error describing it.
+func WithFormats(formats ...Format) Option {
+	return func(o *options) {
+		for _, f := range formats {
+			if err := f.validate(); err != nil && o.err == nil {
+				o.err = fmt.Errorf("mdfm: WithFormats: %w", err)
+			}
+		}
+		o.customFormats = append(o.customFormats, formats...)
+	}
+}
+
+func (f Format) validate() error {
+	switch {
+	case f.Name == "":
+		return errors.New("format has no name")
+	case strings.TrimSpace(f.Start) == "" || strings.TrimSpace(f.End) == "":
+		return fmt.Errorf("format %q has an empty delimiter", f.Name)
+	case f.Unmarshal == nil:
+		return fmt.Errorf("format %q has no Unmarshal function", f.Name)
+	}
+	return nil
+}
+
+// detectedFormats returns the formats to detect, in order of precedence.
+func detectedFormats(custom []Format) []*markdown.Format {
+	registry.RLock()
+	formats := slices.Concat(custom, registry.formats)
+	registry.RUnlock()
+
+	if len(formats) == 0 {
+		return nil
+	}
+
+	detected := make([]*markdown.Format, 0, len(formats))
+	for _, f := range formats {
+		detected = append(detected, &markdown.Format{
+			Name:      f.Name,
+			Start:     strings.TrimSpace(f.Start),
+			End:       strings.TrimSpace(f.End),
+			Unmarshal: f.Unmarshal,
+			FieldTag:  f.FieldTag,
+		})
+	}
+	return append(detected, markdown.DefaultFormats()...)
+}
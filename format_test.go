@@ -0,0 +1,136 @@
+package mdfm_test
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/sushichan044/mdfm"
+)
+
+func htmlCommentFormat() mdfm.Format {
+	return mdfm.Format{
+		Name:      mdfm.FormatYAML,
+		Start:     "<!-- ---",
+		End:       "--- -->",
+		Unmarshal: yaml.Unmarshal,
+		FieldTag:  "yaml",
+	}
+}
+
+func TestWithFormats(t *testing.T) {
+	input := "<!-- ---\ntitle: Hidden\n--- -->\nBody"
+
+	t.Run("not detected without the format", func(t *testing.T) {
+		doc, err := mdfm.ParseBytes[testMetadata]([]byte(input))
+		require.NoError(t, err)
+		assert.Empty(t, doc.FrontMatter.Title)
+		assert.Equal(t, input, doc.BodyString())
+	})
+
+	t.Run("detected with the format", func(t *testing.T) {
+		doc, err := mdfm.ParseBytes[testMetadata](
+			[]byte(input),
+			mdfm.WithFormats(htmlCommentFormat()),
+			mdfm.WithPositions(),
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t, "Hidden", doc.FrontMatter.Title)
+		assert.Equal(t, mdfm.FormatYAML, doc.Format)
+		assert.Equal(t, "Body", doc.BodyString())
+		assert.Equal(t, 4, doc.BodyStartLine)
+		assert.Equal(t, mdfm.Position{Line: 2, Column: 1}, doc.Positions["title"])
+	})
+
+	t.Run("built-in formats are still detected", func(t *testing.T) {
+		doc, err := mdfm.ParseBytes[testMetadata](
+			[]byte("+++\ntitle = \"TOML\"\n+++\nBody"),
+			mdfm.WithFormats(htmlCommentFormat()),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, "TOML", doc.FrontMatter.Title)
+	})
+
+	t.Run("custom formats take precedence", func(t *testing.T) {
+		jsonDashes := mdfm.Format{Name: "json-dashes", Start: "---", End: "---", Unmarshal: json.Unmarshal}
+
+		doc, err := mdfm.ParseBytes[map[string]any](
+			[]byte("---\n{\"title\": \"JSON\"}\n---\nBody"),
+			mdfm.WithFormats(jsonDashes),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, "json-dashes", doc.Format)
+		assert.Equal(t, "JSON", doc.FrontMatter["title"])
+	})
+
+	t.Run("decode errors report the custom format", func(t *testing.T) {
+		_, err := mdfm.ParseBytes[testMetadata](
+			[]byte("<!-- ---\ntitle: [unclosed\n--- -->\nBody"),
+			mdfm.WithFormats(htmlCommentFormat()),
+		)
+
+		var parseErr *mdfm.ParseError
+		require.ErrorAs(t, err, &parseErr)
+		assert.Equal(t, mdfm.FormatYAML, parseErr.Format)
+		assert.Equal(t, 2, parseErr.Line)
+	})
+
+	t.Run("glob", func(t *testing.T) {
+		fsys := fstest.MapFS{"post.md": {Data: []byte(input)}}
+
+		results, err := mdfm.GlobFS[testMetadata](fsys, "*.md", mdfm.WithFormats(htmlCommentFormat()))
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		require.NoError(t, results[0].Err)
+		assert.Equal(t, "Hidden", results[0].Document.FrontMatter.Title)
+	})
+}
+
+func TestWithFormats_Invalid(t *testing.T) {
+	tests := map[string]mdfm.Format{
+		"no name":      {Start: "<!--", End: "-->", Unmarshal: yaml.Unmarshal},
+		"no start":     {Name: "x", Start: " ", End: "-->", Unmarshal: yaml.Unmarshal},
+		"no unmarshal": {Name: "x", Start: "<!--", End: "-->"},
+	}
+
+	for name, f := range tests {
+		t.Run(name, func(t *testing.T) {
+			opt := mdfm.WithFormats(htmlCommentFormat(), f)
+
+			_, err := mdfm.ParseBytes[testMetadata]([]byte("---\ntitle: a\n---\n"), opt)
+			require.ErrorContains(t, err, "mdfm: WithFormats: ")
+
+			results, err := mdfm.GlobFS[testMetadata](fstest.MapFS{"a.md": {Data: []byte("# a\n")}}, "*.md", opt)
+			require.ErrorContains(t, err, "mdfm: WithFormats: ")
+			assert.Nil(t, results)
+
+			_, err = mdfm.Edit(filepath.Join(t.TempDir(), "*.md"), func(*mdfm.OrderedMap) error { return nil }, opt)
+			require.ErrorContains(t, err, "mdfm: WithFormats: ")
+
+			assert.Panics(t, func() { mdfm.RegisterFormat(f) })
+		})
+	}
+}
+
+func TestRegisterFormat(t *testing.T) {
+	// Registered formats are global; use delimiters no other test relies on.
+	mdfm.RegisterFormat(mdfm.Format{
+		Name:      "yaml-percent",
+		Start:     "%%%",
+		End:       "%%%",
+		Unmarshal: yaml.Unmarshal,
+	})
+
+	doc, err := mdfm.ParseBytes[testMetadata]([]byte("%%%\ntitle: Registered\n%%%\nBody"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "yaml-percent", doc.Format)
+	assert.Equal(t, "Registered", doc.FrontMatter.Title)
+	assert.Equal(t, "Body", doc.BodyString())
+}
@@ -5,24 +5,25 @@ go 1.24.0
 toolchain go1.25.1
 
 require (
+	github.com/BurntSushi/toml v1.5.0
 	github.com/Songmu/gitconfig v0.2.1
-	github.com/adrg/frontmatter v0.2.0
 	github.com/alecthomas/kong v1.12.1
 	github.com/basemachina/lo v0.0.0-20250618012814-7ae329aee0ca
 	github.com/bmatcuk/doublestar/v4 v4.9.1
 	github.com/sabhiram/go-gitignore v0.0.0-20210923224102-525f6e181f06
+	github.com/santhosh-tekuri/jsonschema/v6 v6.0.2
 	github.com/stretchr/testify v1.11.1
 	golang.org/x/sync v0.16.0
+	gopkg.in/yaml.v2 v2.3.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
-	github.com/BurntSushi/toml v0.3.1 // indirect
 	github.com/cli/go-gh/v2 v2.12.1 // indirect
 	github.com/cli/safeexec v1.0.1 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/goccy/go-yaml v1.18.0 // indirect
 	github.com/kr/text v0.2.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v2 v2.3.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	golang.org/x/text v0.23.0 // indirect
 )
@@ -0,0 +1,282 @@
+// Package codegen generates type definitions for frontmatter from an inferred schema.
+package codegen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sushichan044/mdfm/internal/infer"
+)
+
+// GoOptions configures the code generated by Go.
+type GoOptions struct {
+	// Package is the name of the package of the generated file.
+	Package string
+
+	// TypeName is the name of the struct type of the frontmatter. Nested mappings get types
+	// named after it, e.g. "PostSEO" for the "seo" key of "Post".
+	TypeName string
+
+	// Tags are the struct tags to generate for each field, e.g. "yaml" and "toml".
+	Tags []string
+
+	// Source describes where the schema was inferred from, e.g. the glob pattern of the files.
+	// It is mentioned in the doc comment of the type.
+	Source string
+}
+
+type (
+	// goGenerator collects the struct types generated for a schema.
+	goGenerator struct {
+		opts    GoOptions
+		types   []goStruct
+		names   map[string]bool
+		imports map[string]bool
+	}
+
+	goStruct struct {
+		name   string
+		doc    string
+		fields []goField
+	}
+
+	goField struct {
+		name      string
+		typ       string
+		key       string
+		omitEmpty bool
+		comment   string
+	}
+)
+
+// Go returns the source of a Go file that declares a struct type for the frontmatter described by
+// schema, suitable for Glob[T]:
+//
+//   - keys that are missing from some files, or null in some, are pointers or have omitempty,
+//   - dates and datetimes are time.Time,
+//   - mappings are nested struct types, and sequences slices of their item type,
+//   - keys whose values have conflicting types are any.
+func Go(schema *infer.Schema, opts GoOptions) ([]byte, error) {
+	if !token.IsIdentifier(opts.Package) {
+		return nil, fmt.Errorf("invalid package name %q", opts.Package)
+	}
+	if !token.IsIdentifier(opts.TypeName) || !token.IsExported(opts.TypeName) {
+		return nil, fmt.Errorf("invalid type name %q: must be an exported identifier", opts.TypeName)
+	}
+	if len(opts.Tags) == 0 {
+		return nil, errors.New("no struct tags to generate")
+	}
+
+	g := &goGenerator{opts: opts, names: map[string]bool{}, imports: map[string]bool{}}
+	doc := fmt.Sprintf("%s is the frontmatter of %d files", opts.TypeName, schema.Documents())
+	if schema.Documents() == 1 {
+		doc = opts.TypeName + " is the frontmatter of 1 file"
+	}
+	if opts.Source != "" {
+		doc += " matched by " + strconv.Quote(opts.Source)
+	}
+	g.structType(opts.TypeName, doc+".", schema.Root())
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by mdfm gen go; DO NOT EDIT.\n\npackage %s\n", opts.Package)
+	if g.imports["time"] {
+		buf.WriteString("\nimport \"time\"\n")
+	}
+	for _, t := range g.types {
+		g.writeStruct(&buf, t)
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return src, nil
+}
+
+// structType declares a struct type named name for the mappings of f.
+func (g *goGenerator) structType(name, doc string, f *infer.Field) string {
+	name = g.uniqueName(name)
+	index := len(g.types)
+	g.types = append(g.types, goStruct{name: name, doc: doc})
+
+	var fields []goField
+	fieldNames := map[string]bool{}
+	for _, prop := range f.Properties() {
+		fieldName := goName(prop.Name)
+		for i := 2; fieldNames[fieldName]; i++ {
+			fieldName = goName(prop.Name) + strconv.Itoa(i)
+		}
+		fieldNames[fieldName] = true
+
+		field := goField{name: fieldName, key: prop.Name}
+		optional := !f.Required(prop) || prop.Nullable()
+		field.typ, field.comment = g.fieldType(name+fieldName, prop)
+		switch {
+		case !optional, field.typ == "any":
+		case strings.HasPrefix(field.typ, "[]"), strings.HasPrefix(field.typ, "map["):
+			field.omitEmpty = true
+		default:
+			field.typ = "*" + field.typ
+			field.omitEmpty = true
+		}
+		fields = append(fields, field)
+	}
+	g.types[index].fields = fields
+	return name
+}
+
+// fieldType returns the Go type of the values of f, and a comment explaining it if needed.
+// name is the name of the struct type to declare if the values are mappings.
+func (g *goGenerator) fieldType(name string, f *infer.Field) (string, string) {
+	kind, ok := f.Kind()
+	if !ok {
+		if f.Conflict() {
+			return "any", "Conflicting types: " + joinKinds(f) + "."
+		}
+		return "any", "Only null values were found."
+	}
+
+	switch kind {
+	case infer.KindString, infer.KindTime:
+		return "string", ""
+	case infer.KindInteger:
+		return "int", ""
+	case infer.KindNumber:
+		return "float64", ""
+	case infer.KindBoolean:
+		return "bool", ""
+	case infer.KindDate, infer.KindDatetime:
+		g.imports["time"] = true
+		return "time.Time", ""
+	case infer.KindArray:
+		if f.Items == nil {
+			return "[]any", "Only empty lists were found."
+		}
+		typ, comment := g.fieldType(name+"Item", f.Items)
+		if strings.HasPrefix(comment, "Conflicting") {
+			comment = "Items have conflicting types: " + joinKinds(f.Items) + "."
+		}
+		return "[]" + typ, comment
+	case infer.KindObject:
+		if len(f.Properties()) == 0 {
+			return "map[string]any", "Only empty mappings were found."
+		}
+		doc := fmt.Sprintf("%s is the value of the %q key.", name, f.Path)
+		if f.Name == infer.ItemsName {
+			doc = fmt.Sprintf("%s is an item of the %q key.", name, strings.TrimSuffix(f.Path, infer.ItemsName))
+		}
+		return g.structType(name, doc, f), ""
+	case infer.KindNull:
+	}
+	return "any", ""
+}
+
+// uniqueName returns name, or name with a numeric suffix if a type of that name was already declared.
+func (g *goGenerator) uniqueName(name string) string {
+	unique := name
+	for i := 2; g.names[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	g.names[unique] = true
+	return unique
+}
+
+func (g *goGenerator) writeStruct(buf *bytes.Buffer, t goStruct) {
+	fmt.Fprintf(buf, "\n// %s\ntype %s struct {\n", t.doc, t.name)
+	for _, f := range t.fields {
+		if f.comment != "" {
+			fmt.Fprintf(buf, "// %s\n", f.comment)
+		}
+		tags := make([]string, len(g.opts.Tags))
+		for i, tag := range g.opts.Tags {
+			value := f.key
+			if f.omitEmpty {
+				value += ",omitempty"
+			}
+			tags[i] = tag + ":" + strconv.Quote(value)
+		}
+		fmt.Fprintf(buf, "%s %s `%s`\n", f.name, f.typ, strings.Join(tags, " "))
+	}
+	buf.WriteString("}\n")
+}
+
+// joinKinds lists the kinds of f, the most frequent first.
+func joinKinds(f *infer.Field) string {
+	kinds := slices.DeleteFunc(f.SortedKinds(), func(k infer.Kind) bool { return k == infer.KindNull })
+	names := make([]string, len(kinds))
+	for i, kind := range kinds {
+		names[i] = string(kind)
+	}
+	return strings.Join(names, ", ")
+}
+
+// commonInitialisms are written in upper case in Go names, as recommended by Go Code Review Comments.
+//
+//nolint:gochecknoglobals // Read-only lookup table.
+var commonInitialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true, "DNS": true, "EOF": true,
+	"GUID": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true,
+	"OG": true, "QPS": true, "RAM": true, "RPC": true, "SEO": true, "SLA": true, "SMTP": true,
+	"SQL": true, "SSH": true, "TCP": true, "TLS": true, "TTL": true, "UDP": true, "UI": true,
+	"UID": true, "URI": true, "URL": true, "UTF8": true, "UUID": true, "VM": true, "XML": true,
+}
+
+// goName converts a frontmatter key such as "allowed-tools" or "seo_title" to an exported
+// Go identifier such as "AllowedTools" or "SEOTitle".
+func goName(key string) string {
+	var sb strings.Builder
+	for _, word := range splitWords(key) {
+		if upper := strings.ToUpper(word); commonInitialisms[upper] {
+			sb.WriteString(upper)
+			continue
+		}
+		sb.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+
+	name := sb.String()
+	if name == "" || !token.IsIdentifier(name) {
+		return "Field" + name
+	}
+	return name
+}
+
+// splitWords splits a key into words at non-alphanumeric characters and lower-to-upper case
+// transitions, e.g. "ogImage_url" into "og", "Image" and "url".
+func splitWords(key string) []string {
+	var words []string
+	var word []rune
+	var prev rune
+	for _, r := range key {
+		switch {
+		case !isAlphanumeric(r):
+			if len(word) > 0 {
+				words = append(words, string(word))
+			}
+			word = nil
+		case len(word) > 0 && isUpper(r) && !isUpper(prev):
+			words = append(words, string(word))
+			word = []rune{r}
+		default:
+			word = append(word, r)
+		}
+		prev = r
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+	return words
+}
+
+func isAlphanumeric(r rune) bool {
+	return r >= 'a' && r <= 'z' || isUpper(r) || r >= '0' && r <= '9'
+}
+
+func isUpper(r rune) bool {
+	return r >= 'A' && r <= 'Z'
+}
@@ -0,0 +1,100 @@
+package codegen_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/mdfm/internal/codegen"
+	"github.com/sushichan044/mdfm/internal/infer"
+	"github.com/sushichan044/mdfm/internal/tree"
+)
+
+func newSchema(t *testing.T, documents ...string) *infer.Schema {
+	t.Helper()
+
+	schema := infer.New()
+	for _, doc := range documents {
+		var om tree.OrderedMap
+		require.NoError(t, json.Unmarshal([]byte(doc), &om))
+		schema.Add(&om)
+	}
+	return schema
+}
+
+func TestGo(t *testing.T) {
+	schema := newSchema(t,
+		`{"title": "A", "date": "2024-01-02", "tags": ["go"], "seo": {"description": "d", "og_image": "a.png"}, "weight": 1}`,
+		`{"title": "B", "date": "2024-01-03T10:00:00Z", "tags": "solo", "authors": [{"name": "x", "email": "x@example.com"}], "allowed-tools": null}`,
+		`{"title": "C", "date": "2024-01-04", "draft": true, "weight": 1.5, "extra": {}}`,
+	)
+
+	src, err := codegen.Go(schema, codegen.GoOptions{
+		Package:  "content",
+		TypeName: "Post",
+		Tags:     []string{"yaml", "toml"},
+		Source:   "posts/*.md",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `// Code generated by mdfm gen go; DO NOT EDIT.
+
+package content
+
+import "time"
+
+// Post is the frontmatter of 3 files matched by "posts/*.md".
+type Post struct {
+	// Only null values were found.
+	AllowedTools any               `+"`"+`yaml:"allowed-tools" toml:"allowed-tools"`+"`"+`
+	Authors      []PostAuthorsItem `+"`"+`yaml:"authors,omitempty" toml:"authors,omitempty"`+"`"+`
+	Date         time.Time         `+"`"+`yaml:"date" toml:"date"`+"`"+`
+	Draft        *bool             `+"`"+`yaml:"draft,omitempty" toml:"draft,omitempty"`+"`"+`
+	// Only empty mappings were found.
+	Extra map[string]any `+"`"+`yaml:"extra,omitempty" toml:"extra,omitempty"`+"`"+`
+	SEO   *PostSEO       `+"`"+`yaml:"seo,omitempty" toml:"seo,omitempty"`+"`"+`
+	// Conflicting types: array, string.
+	Tags   any      `+"`"+`yaml:"tags" toml:"tags"`+"`"+`
+	Title  string   `+"`"+`yaml:"title" toml:"title"`+"`"+`
+	Weight *float64 `+"`"+`yaml:"weight,omitempty" toml:"weight,omitempty"`+"`"+`
+}
+
+// PostAuthorsItem is an item of the "authors" key.
+type PostAuthorsItem struct {
+	Email string `+"`"+`yaml:"email" toml:"email"`+"`"+`
+	Name  string `+"`"+`yaml:"name" toml:"name"`+"`"+`
+}
+
+// PostSEO is the value of the "seo" key.
+type PostSEO struct {
+	Description string `+"`"+`yaml:"description" toml:"description"`+"`"+`
+	OGImage     string `+"`"+`yaml:"og_image" toml:"og_image"`+"`"+`
+}
+`, string(src))
+}
+
+func TestGo_Names(t *testing.T) {
+	schema := newSchema(t, `{"userId": 1, "user_id": 2, "2fa": true, "_": "x"}`)
+
+	src, err := codegen.Go(schema, codegen.GoOptions{Package: "main", TypeName: "FrontMatter", Tags: []string{"json"}})
+	require.NoError(t, err)
+	assert.Contains(t, string(src), "// FrontMatter is the frontmatter of 1 file.\n")
+	assert.Contains(t, string(src), "Field2fa bool   `json:\"2fa\"`")
+	assert.Contains(t, string(src), "Field    string `json:\"_\"`")
+	assert.Contains(t, string(src), "UserID   int    `json:\"userId\"`")
+	assert.Contains(t, string(src), "UserID2  int    `json:\"user_id\"`")
+}
+
+func TestGo_InvalidOptions(t *testing.T) {
+	schema := infer.New()
+
+	for _, opts := range []codegen.GoOptions{
+		{Package: "my-package", TypeName: "FrontMatter", Tags: []string{"yaml"}},
+		{Package: "main", TypeName: "frontMatter", Tags: []string{"yaml"}},
+		{Package: "main", TypeName: "FrontMatter"},
+	} {
+		_, err := codegen.Go(schema, opts)
+		assert.Error(t, err, "%+v", opts)
+	}
+}
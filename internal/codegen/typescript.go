@@ -0,0 +1,481 @@
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/sushichan044/mdfm/internal/tree"
+)
+
+// TypeScriptOptions configures the code generated by TypeScript.
+type TypeScriptOptions struct {
+	// TypeName is the name of the type of the frontmatter.
+	TypeName string
+
+	// DocumentTypeName is the name of the type of the objects printed by the CLI, whose
+	// "frontMatter" property has the type TypeName.
+	DocumentTypeName string
+
+	// Zod generates zod schemas named after the types, e.g. "frontMatterSchema", and the types
+	// inferred from them, instead of interfaces.
+	Zod bool
+
+	// Description is written as the doc comment of the frontmatter type, if not empty.
+	Description string
+}
+
+type (
+	// tsNode is a TypeScript type, or zod schema, converted from a JSON Schema.
+	tsNode struct {
+		kind    tsKind
+		literal any
+		ref     string
+		items   *tsNode
+		props   []tsProp
+		members []*tsNode
+	}
+
+	tsProp struct {
+		name     string
+		node     *tsNode
+		optional bool
+	}
+
+	tsKind int
+
+	// tsGenerator converts the subschemas of a JSON Schema into tsNodes.
+	tsGenerator struct {
+		root *tree.OrderedMap
+		// resolving holds the references being resolved, to stop at recursive references.
+		resolving map[string]bool
+	}
+)
+
+const (
+	tsUnknown tsKind = iota
+	tsNever
+	tsString
+	tsNumber
+	tsInteger
+	tsBoolean
+	tsNull
+	tsLiteral
+	tsArray
+	tsObject
+	tsRecord
+	tsUnion
+	tsIntersection
+	// tsRef is a reference to a declared type or schema, printed as is.
+	tsRef
+)
+
+// TypeScript returns the source of a TypeScript module that declares the type of frontmatter
+// matching schema, a JSON Schema, and the type of the JSON objects printed by the CLI.
+//
+// The keywords that describe the shape of values are supported: "type", "properties",
+// "required", "additionalProperties", "items", "enum", "const", "anyOf", "oneOf", "allOf"
+// and local "$ref"s. Other keywords, such as "format" or "minLength", are ignored, and
+// recursive references are typed as unknown.
+func TypeScript(schema *tree.OrderedMap, opts TypeScriptOptions) ([]byte, error) {
+	for _, name := range []string{opts.TypeName, opts.DocumentTypeName} {
+		if !isJSIdentifier(name) {
+			return nil, fmt.Errorf("invalid type name %q", name)
+		}
+	}
+
+	g := &tsGenerator{root: schema, resolving: map[string]bool{}}
+	frontMatter := g.node(schema)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by mdfm gen ts; DO NOT EDIT.\n")
+	if opts.Zod {
+		frontMatterSchema := schemaName(opts.TypeName)
+		document := documentNode(frontMatterSchema)
+
+		buf.WriteString("\nimport { z } from \"zod\";\n")
+		writeDocComment(&buf, opts.Description)
+		fmt.Fprintf(&buf, "export const %s = %s;\n", frontMatterSchema, zodType(frontMatter, 0))
+		fmt.Fprintf(&buf, "export type %s = z.infer<typeof %s>;\n", opts.TypeName, frontMatterSchema)
+
+		writeDocComment(&buf, "A document as printed by mdfm.")
+		fmt.Fprintf(&buf, "export const %s = %s;\n", schemaName(opts.DocumentTypeName), zodType(document, 0))
+		fmt.Fprintf(&buf, "export type %s = z.infer<typeof %s>;\n", opts.DocumentTypeName, schemaName(opts.DocumentTypeName))
+		return buf.Bytes(), nil
+	}
+
+	document := documentNode(opts.TypeName)
+	writeDocComment(&buf, opts.Description)
+	if frontMatter.kind == tsObject {
+		fmt.Fprintf(&buf, "export interface %s %s\n", opts.TypeName, tsType(frontMatter, 0))
+	} else {
+		fmt.Fprintf(&buf, "export type %s = %s;\n", opts.TypeName, tsType(frontMatter, 0))
+	}
+	writeDocComment(&buf, "A document as printed by mdfm.")
+	fmt.Fprintf(&buf, "export interface %s %s\n", opts.DocumentTypeName, tsType(document, 0))
+	return buf.Bytes(), nil
+}
+
+// documentNode returns the type of the JSON objects printed by the CLI. frontMatter is the
+// name of the type, or schema, of the frontmatter, which is null for documents without one.
+func documentNode(frontMatter string) *tsNode {
+	return &tsNode{kind: tsObject, props: []tsProp{
+		{name: "path", node: &tsNode{kind: tsString}},
+		{name: "frontMatter", node: &tsNode{kind: tsUnion, members: []*tsNode{
+			{kind: tsRef, ref: frontMatter},
+			{kind: tsNull},
+		}}},
+		{name: "body", node: &tsNode{kind: tsString}},
+		{name: "format", node: &tsNode{kind: tsString}, optional: true},
+		{name: "rawFrontMatter", node: &tsNode{kind: tsString}, optional: true},
+		{name: "bodyStartLine", node: &tsNode{kind: tsInteger}, optional: true},
+		{name: "positions", node: &tsNode{kind: tsRecord, items: &tsNode{kind: tsObject, props: []tsProp{
+			{name: "line", node: &tsNode{kind: tsInteger}},
+			{name: "column", node: &tsNode{kind: tsInteger}},
+		}}}, optional: true},
+	}}
+}
+
+// node converts a JSON Schema into a tsNode.
+func (g *tsGenerator) node(schema any) *tsNode {
+	switch s := schema.(type) {
+	case bool:
+		if !s {
+			return &tsNode{kind: tsNever}
+		}
+		return &tsNode{kind: tsUnknown}
+	case *tree.OrderedMap:
+		return g.objectNode(s)
+	default:
+		return &tsNode{kind: tsUnknown}
+	}
+}
+
+func (g *tsGenerator) objectNode(s *tree.OrderedMap) *tsNode {
+	if ref, ok := s.Get("$ref"); ok {
+		return g.refNode(fmt.Sprint(ref))
+	}
+	if value, ok := s.Get("const"); ok {
+		return &tsNode{kind: tsLiteral, literal: value}
+	}
+	if values, ok := get(s, "enum").([]any); ok {
+		union := &tsNode{kind: tsUnion}
+		for _, value := range values {
+			union.members = append(union.members, &tsNode{kind: tsLiteral, literal: value})
+		}
+		return union
+	}
+	for _, keyword := range []string{"anyOf", "oneOf", "allOf"} {
+		if subschemas, ok := get(s, keyword).([]any); ok {
+			kind := tsUnion
+			if keyword == "allOf" {
+				kind = tsIntersection
+			}
+			node := &tsNode{kind: kind}
+			for _, sub := range subschemas {
+				node.members = append(node.members, g.node(sub))
+			}
+			return node
+		}
+	}
+
+	switch typ := get(s, "type").(type) {
+	case string:
+		return g.typedNode(s, typ)
+	case []any:
+		union := &tsNode{kind: tsUnion}
+		for _, t := range typ {
+			union.members = append(union.members, g.typedNode(s, fmt.Sprint(t)))
+		}
+		return union
+	}
+	switch {
+	case get(s, "properties") != nil:
+		return g.typedNode(s, "object")
+	case get(s, "items") != nil:
+		return g.typedNode(s, "array")
+	default:
+		return &tsNode{kind: tsUnknown}
+	}
+}
+
+// typedNode converts a JSON Schema of the given "type" into a tsNode.
+func (g *tsGenerator) typedNode(s *tree.OrderedMap, typ string) *tsNode {
+	switch typ {
+	case "string":
+		return &tsNode{kind: tsString}
+	case "number":
+		return &tsNode{kind: tsNumber}
+	case "integer":
+		return &tsNode{kind: tsInteger}
+	case "boolean":
+		return &tsNode{kind: tsBoolean}
+	case "null":
+		return &tsNode{kind: tsNull}
+	case "array":
+		items, ok := s.Get("items")
+		if !ok {
+			return &tsNode{kind: tsArray, items: &tsNode{kind: tsUnknown}}
+		}
+		return &tsNode{kind: tsArray, items: g.node(items)}
+	case "object":
+		properties, _ := get(s, "properties").(*tree.OrderedMap)
+		if properties == nil || properties.Len() == 0 {
+			values, ok := s.Get("additionalProperties")
+			if !ok {
+				values = true
+			}
+			return &tsNode{kind: tsRecord, items: g.node(values)}
+		}
+
+		required, _ := get(s, "required").([]any)
+		node := &tsNode{kind: tsObject}
+		for name, prop := range properties.All() {
+			node.props = append(node.props, tsProp{
+				name:     name,
+				node:     g.node(prop),
+				optional: !slices.Contains(required, any(name)),
+			})
+		}
+		return node
+	default:
+		return &tsNode{kind: tsUnknown}
+	}
+}
+
+// refNode resolves a reference to a subschema of the root schema, such as "#/$defs/author".
+func (g *tsGenerator) refNode(ref string) *tsNode {
+	pointer, ok := strings.CutPrefix(ref, "#")
+	if !ok || g.resolving[ref] {
+		return &tsNode{kind: tsUnknown}
+	}
+
+	var target any = g.root
+	for token := range strings.SplitSeq(strings.TrimPrefix(pointer, "/"), "/") {
+		if token == "" {
+			continue
+		}
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		om, isMap := target.(*tree.OrderedMap)
+		if !isMap {
+			return &tsNode{kind: tsUnknown}
+		}
+		if target, ok = om.Get(token); !ok {
+			return &tsNode{kind: tsUnknown}
+		}
+	}
+
+	g.resolving[ref] = true
+	defer delete(g.resolving, ref)
+	return g.node(target)
+}
+
+// tsType prints node as a TypeScript type. Object types are indented by indent levels.
+func tsType(node *tsNode, indent int) string {
+	switch node.kind {
+	case tsNever:
+		return "never"
+	case tsString:
+		return "string"
+	case tsNumber, tsInteger:
+		return "number"
+	case tsBoolean:
+		return "boolean"
+	case tsNull:
+		return "null"
+	case tsLiteral:
+		return literal(node.literal)
+	case tsRef:
+		return node.ref
+	case tsArray:
+		items := tsType(node.items, indent)
+		if node.items.kind == tsUnion || node.items.kind == tsIntersection {
+			items = "(" + items + ")"
+		}
+		return items + "[]"
+	case tsRecord:
+		return "Record<string, " + tsType(node.items, indent) + ">"
+	case tsObject:
+		var sb strings.Builder
+		sb.WriteString("{\n")
+		for _, prop := range node.props {
+			optional := ""
+			if prop.optional {
+				optional = "?"
+			}
+			fmt.Fprintf(&sb, "%s%s%s: %s;\n", tsIndent(indent+1), propertyName(prop.name), optional, tsType(prop.node, indent+1))
+		}
+		sb.WriteString(tsIndent(indent) + "}")
+		return sb.String()
+	case tsUnion, tsIntersection:
+		sep := " | "
+		if node.kind == tsIntersection {
+			sep = " & "
+		}
+		var members []string
+		for _, member := range node.members {
+			if m := tsType(member, indent); !slices.Contains(members, m) {
+				members = append(members, m)
+			}
+		}
+		if len(members) == 0 {
+			return "never"
+		}
+		return strings.Join(members, sep)
+	case tsUnknown:
+	}
+	return "unknown"
+}
+
+// zodType prints node as a zod schema. Object schemas are indented by indent levels.
+func zodType(node *tsNode, indent int) string {
+	switch node.kind {
+	case tsNever:
+		return "z.never()"
+	case tsString:
+		return "z.string()"
+	case tsNumber:
+		return "z.number()"
+	case tsInteger:
+		return "z.number().int()"
+	case tsBoolean:
+		return "z.boolean()"
+	case tsNull:
+		return "z.null()"
+	case tsLiteral:
+		return "z.literal(" + literal(node.literal) + ")"
+	case tsRef:
+		return node.ref
+	case tsArray:
+		return "z.array(" + zodType(node.items, indent) + ")"
+	case tsRecord:
+		return "z.record(z.string(), " + zodType(node.items, indent) + ")"
+	case tsObject:
+		var sb strings.Builder
+		sb.WriteString("z.object({\n")
+		for _, prop := range node.props {
+			value := zodType(prop.node, indent+1)
+			if prop.optional {
+				value += ".optional()"
+			}
+			fmt.Fprintf(&sb, "%s%s: %s,\n", tsIndent(indent+1), propertyName(prop.name), value)
+		}
+		sb.WriteString(tsIndent(indent) + "})")
+		return sb.String()
+	case tsUnion:
+		return zodUnion(node, indent)
+	case tsIntersection:
+		if len(node.members) == 0 {
+			return "z.unknown()"
+		}
+		schema := zodType(node.members[0], indent)
+		for _, member := range node.members[1:] {
+			schema += ".and(" + zodType(member, indent) + ")"
+		}
+		return schema
+	case tsUnknown:
+	}
+	return "z.unknown()"
+}
+
+func zodUnion(node *tsNode, indent int) string {
+	var members []string
+	nullable, enum := false, true
+	for _, member := range node.members {
+		if member.kind == tsNull {
+			nullable = true
+			continue
+		}
+		if _, isString := member.literal.(string); member.kind != tsLiteral || !isString {
+			enum = false
+		}
+		if m := zodType(member, indent); !slices.Contains(members, m) {
+			members = append(members, m)
+		}
+	}
+
+	var schema string
+	switch {
+	case len(members) == 0 && nullable:
+		return "z.null()"
+	case len(members) == 0:
+		return "z.never()"
+	case len(members) == 1:
+		schema = members[0]
+	case enum:
+		values := make([]string, len(members))
+		for i, m := range members {
+			values[i] = strings.TrimSuffix(strings.TrimPrefix(m, "z.literal("), ")")
+		}
+		schema = "z.enum([" + strings.Join(values, ", ") + "])"
+	default:
+		schema = "z.union([" + strings.Join(members, ", ") + "])"
+	}
+	if nullable {
+		schema += ".nullable()"
+	}
+	return schema
+}
+
+// literal prints a JSON value as a TypeScript literal.
+func literal(v any) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return "null"
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// propertyName prints the name of a property, quoted if it is not an identifier.
+func propertyName(name string) string {
+	if isJSIdentifier(name) {
+		return name
+	}
+	return literal(name)
+}
+
+// schemaName returns the name of the zod schema of a type, e.g. "frontMatterSchema" for "FrontMatter".
+func schemaName(typeName string) string {
+	return strings.ToLower(typeName[:1]) + typeName[1:] + "Schema"
+}
+
+// writeDocComment writes a blank line followed by doc as a JSDoc comment, if not empty.
+func writeDocComment(buf *bytes.Buffer, doc string) {
+	if doc != "" {
+		// Glob patterns such as "**/*.md" would end the comment.
+		fmt.Fprintf(buf, "\n/** %s */\n", strings.ReplaceAll(doc, "*/", "*\\/"))
+	} else {
+		buf.WriteString("\n")
+	}
+}
+
+func tsIndent(level int) string {
+	return strings.Repeat("  ", level)
+}
+
+// get returns the value of key in s, or nil if it is not set.
+func get(s *tree.OrderedMap, key string) any {
+	v, _ := s.Get(key)
+	return v
+}
+
+// isJSIdentifier reports whether name can be used as an identifier or a property name
+// without quotes. Only ASCII identifiers are considered.
+func isJSIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_' || r == '$' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+		case i > 0 && r >= '0' && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
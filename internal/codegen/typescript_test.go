@@ -0,0 +1,148 @@
+package codegen_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/mdfm/internal/codegen"
+	"github.com/sushichan044/mdfm/internal/tree"
+)
+
+const testJSONSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "properties": {
+    "title": {"type": "string", "minLength": 1},
+    "status": {"enum": ["draft", "published"]},
+    "layout": {"const": "post"},
+    "weight": {"type": ["integer", "null"]},
+    "tags": {"type": "array", "items": {"anyOf": [{"type": "string"}, {"type": "number"}]}},
+    "author": {"$ref": "#/$defs/author"},
+    "extra": {"type": "object", "additionalProperties": {"type": "string"}},
+    "seo": {"allOf": [{"$ref": "#/$defs/meta"}, {"type": "object", "properties": {"noindex": {"type": "boolean"}}}]}
+  },
+  "required": ["title", "status"],
+  "$defs": {
+    "author": {"type": "object", "properties": {"name": {"type": "string"}, "og:image": {"type": "string"}, "next": {"$ref": "#/$defs/author"}}, "required": ["name"]},
+    "meta": {"properties": {"description": {"type": "string"}}}
+  }
+}`
+
+func parseJSONSchema(t *testing.T, schema string) *tree.OrderedMap {
+	t.Helper()
+
+	var om tree.OrderedMap
+	require.NoError(t, json.Unmarshal([]byte(schema), &om))
+	return &om
+}
+
+func TestTypeScript(t *testing.T) {
+	src, err := codegen.TypeScript(parseJSONSchema(t, testJSONSchema), codegen.TypeScriptOptions{
+		TypeName:         "Post",
+		DocumentTypeName: "PostDocument",
+		Description:      `Frontmatter of the files matched by "content/**/*.md".`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `// Code generated by mdfm gen ts; DO NOT EDIT.
+
+/** Frontmatter of the files matched by "content/**\/*.md". */
+export interface Post {
+  title: string;
+  status: "draft" | "published";
+  layout?: "post";
+  weight?: number | null;
+  tags?: (string | number)[];
+  author?: {
+    name: string;
+    "og:image"?: string;
+    next?: unknown;
+  };
+  extra?: Record<string, string>;
+  seo?: {
+    description?: string;
+  } & {
+    noindex?: boolean;
+  };
+}
+
+/** A document as printed by mdfm. */
+export interface PostDocument {
+  path: string;
+  frontMatter: Post | null;
+  body: string;
+  format?: string;
+  rawFrontMatter?: string;
+  bodyStartLine?: number;
+  positions?: Record<string, {
+    line: number;
+    column: number;
+  }>;
+}
+`, string(src))
+}
+
+func TestTypeScript_Zod(t *testing.T) {
+	src, err := codegen.TypeScript(parseJSONSchema(t, testJSONSchema), codegen.TypeScriptOptions{
+		TypeName:         "FrontMatter",
+		DocumentTypeName: "Document",
+		Zod:              true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `// Code generated by mdfm gen ts; DO NOT EDIT.
+
+import { z } from "zod";
+
+export const frontMatterSchema = z.object({
+  title: z.string(),
+  status: z.enum(["draft", "published"]),
+  layout: z.literal("post").optional(),
+  weight: z.number().int().nullable().optional(),
+  tags: z.array(z.union([z.string(), z.number()])).optional(),
+  author: z.object({
+    name: z.string(),
+    "og:image": z.string().optional(),
+    next: z.unknown().optional(),
+  }).optional(),
+  extra: z.record(z.string(), z.string()).optional(),
+  seo: z.object({
+    description: z.string().optional(),
+  }).and(z.object({
+    noindex: z.boolean().optional(),
+  })).optional(),
+});
+export type FrontMatter = z.infer<typeof frontMatterSchema>;
+
+/** A document as printed by mdfm. */
+export const documentSchema = z.object({
+  path: z.string(),
+  frontMatter: frontMatterSchema.nullable(),
+  body: z.string(),
+  format: z.string().optional(),
+  rawFrontMatter: z.string().optional(),
+  bodyStartLine: z.number().int().optional(),
+  positions: z.record(z.string(), z.object({
+    line: z.number().int(),
+    column: z.number().int(),
+  })).optional(),
+});
+export type Document = z.infer<typeof documentSchema>;
+`, string(src))
+}
+
+func TestTypeScript_NonObject(t *testing.T) {
+	src, err := codegen.TypeScript(parseJSONSchema(t, `{}`), codegen.TypeScriptOptions{
+		TypeName:         "FrontMatter",
+		DocumentTypeName: "Document",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, string(src), "\nexport type FrontMatter = unknown;\n")
+
+	_, err = codegen.TypeScript(parseJSONSchema(t, `{}`), codegen.TypeScriptOptions{
+		TypeName:         "Front-Matter",
+		DocumentTypeName: "Document",
+	})
+	require.Error(t, err)
+}
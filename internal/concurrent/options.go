@@ -12,13 +12,6 @@ const (
 	defaultMaxConcurrency = 10
 )
 
-var (
-	//nolint:gochecknoglobals // defaultOpts is safe to use as a package-level variable.
-	defaultOpts = &concurrency{
-		maxConcurrency: defaultMaxConcurrency,
-	}
-)
-
 // WithMaxConcurrency sets the maximum concurrency for running tasks.
 func WithMaxConcurrency(n int64) ConcurrencyOptions {
 	return func(r *concurrency) {
@@ -27,7 +20,10 @@ func WithMaxConcurrency(n int64) ConcurrencyOptions {
 }
 
 func setOpts(options ...ConcurrencyOptions) *concurrency {
-	opts := defaultOpts
+	// Build a fresh value on every call so that options never leak between runs.
+	opts := &concurrency{
+		maxConcurrency: defaultMaxConcurrency,
+	}
 	for _, o := range options {
 		o(opts)
 	}
@@ -3,6 +3,7 @@ package concurrent
 import (
 	"context"
 	"fmt"
+	"iter"
 	"sync"
 
 	"golang.org/x/sync/semaphore"
@@ -37,9 +38,20 @@ type (
 //
 // Concurrency safety: each goroutine writes to a distinct index in the results slice.
 func RunAll[T, M any](tasks []Task[T, M], options ...ConcurrencyOptions) []TaskExecution[T, M] {
-	opts := setOpts(options...)
+	return RunAllContext(context.Background(), tasks, options...)
+}
 
-	ctx := context.Background()
+// RunAllContext is like RunAll but stops starting new tasks once ctx is done.
+//
+// Tasks that are already running are allowed to finish. Tasks that have not started yet
+// (including those waiting for a concurrency slot) are not run; their Result.Err wraps ctx.Err(),
+// so callers can detect them with errors.Is(err, context.Canceled).
+func RunAllContext[T, M any](
+	ctx context.Context,
+	tasks []Task[T, M],
+	options ...ConcurrencyOptions,
+) []TaskExecution[T, M] {
+	opts := setOpts(options...)
 	sem := semaphore.NewWeighted(opts.maxConcurrency)
 
 	var wg sync.WaitGroup
@@ -50,41 +62,7 @@ func RunAll[T, M any](tasks []Task[T, M], options ...ConcurrencyOptions) []TaskE
 
 		go func(i int, task Task[T, M]) {
 			defer wg.Done()
-			var zero T
-
-			if err := sem.Acquire(ctx, 1); err != nil {
-				results[i] = TaskExecution[T, M]{
-					Metadata: task.Metadata,
-					Result: taskResult[T]{
-						Value: zero,
-						Err:   fmt.Errorf("semaphore acquire failed: %w", err),
-					},
-				}
-				return
-			}
-			defer sem.Release(1)
-
-			// Recover panic and convert into error.
-			defer func() {
-				if rec := recover(); rec != nil {
-					results[i] = TaskExecution[T, M]{
-						Metadata: task.Metadata,
-						Result: taskResult[T]{
-							Value: zero,
-							Err:   fmt.Errorf("panic: %v", rec),
-						},
-					}
-				}
-			}()
-
-			v, err := task.Run()
-			results[i] = TaskExecution[T, M]{
-				Metadata: task.Metadata,
-				Result: taskResult[T]{
-					Value: v,
-					Err:   err,
-				},
-			}
+			results[i] = runTask(ctx, sem, task)
 		}(i, task)
 	}
 
@@ -104,9 +82,19 @@ func RunAll[T, M any](tasks []Task[T, M], options ...ConcurrencyOptions) []TaskE
 //
 // The returned channel should be consumed until it's closed to avoid goroutine leaks.
 func RunAllStream[T, M any](tasks []Task[T, M], options ...ConcurrencyOptions) <-chan TaskExecution[T, M] {
-	opts := setOpts(options...)
+	return RunAllStreamContext(context.Background(), tasks, options...)
+}
 
-	ctx := context.Background()
+// RunAllStreamContext is like RunAllStream but stops starting new tasks once ctx is done.
+//
+// Tasks that have not started yet are reported with a Result.Err wrapping ctx.Err() instead of being run,
+// so the channel is closed as soon as the tasks that were already running finish.
+func RunAllStreamContext[T, M any](
+	ctx context.Context,
+	tasks []Task[T, M],
+	options ...ConcurrencyOptions,
+) <-chan TaskExecution[T, M] {
+	opts := setOpts(options...)
 	sem := semaphore.NewWeighted(opts.maxConcurrency)
 
 	// Buffered channel sized to hold all results prevents goroutines from blocking
@@ -119,41 +107,7 @@ func RunAllStream[T, M any](tasks []Task[T, M], options ...ConcurrencyOptions) <
 
 		go func(task Task[T, M]) {
 			defer wg.Done()
-			var zero T
-
-			if err := sem.Acquire(ctx, 1); err != nil {
-				resultChan <- TaskExecution[T, M]{
-					Metadata: task.Metadata,
-					Result: taskResult[T]{
-						Value: zero,
-						Err:   fmt.Errorf("semaphore acquire failed: %w", err),
-					},
-				}
-				return
-			}
-			defer sem.Release(1)
-
-			// Recover panic and convert into error.
-			defer func() {
-				if rec := recover(); rec != nil {
-					resultChan <- TaskExecution[T, M]{
-						Metadata: task.Metadata,
-						Result: taskResult[T]{
-							Value: zero,
-							Err:   fmt.Errorf("panic: %v", rec),
-						},
-					}
-				}
-			}()
-
-			v, err := task.Run()
-			resultChan <- TaskExecution[T, M]{
-				Metadata: task.Metadata,
-				Result: taskResult[T]{
-					Value: v,
-					Err:   err,
-				},
-			}
+			resultChan <- runTask(ctx, sem, task)
 		}(task)
 	}
 
@@ -165,3 +119,106 @@ func RunAllStream[T, M any](tasks []Task[T, M], options ...ConcurrencyOptions) <
 
 	return resultChan
 }
+
+// RunSeqContext runs the tasks produced by tasks concurrently and yields their results in completion
+// order, like RunAllStreamContext, but with backpressure: tasks are pulled from tasks lazily, and a new
+// one is only started once the result of another has been consumed. At most maxConcurrency tasks run
+// ahead of the consumer, however slow it is.
+//
+// Breaking out of the loop stops pulling tasks. The tasks that are running finish in the background
+// and their results are dropped.
+func RunSeqContext[T, M any](
+	ctx context.Context,
+	tasks iter.Seq[Task[T, M]],
+	options ...ConcurrencyOptions,
+) iter.Seq[TaskExecution[T, M]] {
+	return func(yield func(TaskExecution[T, M]) bool) {
+		opts := setOpts(options...)
+		sem := semaphore.NewWeighted(opts.maxConcurrency)
+
+		next, stop := iter.Pull(tasks)
+		defer stop()
+
+		// At most maxConcurrency tasks are running, so they never block on a full channel,
+		// even after the consumer is gone.
+		resultChan := make(chan TaskExecution[T, M], opts.maxConcurrency)
+		var running int64
+		start := func() bool {
+			task, ok := next()
+			if !ok {
+				return false
+			}
+			running++
+			go func() {
+				resultChan <- runTask(ctx, sem, task)
+			}()
+			return true
+		}
+
+		for running < opts.maxConcurrency {
+			if !start() {
+				break
+			}
+		}
+		for running > 0 {
+			execution := <-resultChan
+			running--
+			if !yield(execution) {
+				return
+			}
+			start()
+		}
+	}
+}
+
+// runTask waits for a concurrency slot and runs a single task.
+// Panics are recovered and converted into errors, and the task is skipped if ctx is done before it starts.
+func runTask[T, M any](ctx context.Context, sem *semaphore.Weighted, task Task[T, M]) TaskExecution[T, M] {
+	var zero T
+
+	if err := sem.Acquire(ctx, 1); err != nil {
+		return TaskExecution[T, M]{
+			Metadata: task.Metadata,
+			Result: taskResult[T]{
+				Value: zero,
+				Err:   fmt.Errorf("semaphore acquire failed: %w", err),
+			},
+		}
+	}
+	defer sem.Release(1)
+
+	// Acquire may succeed without blocking even if ctx is already done.
+	if err := ctx.Err(); err != nil {
+		return TaskExecution[T, M]{
+			Metadata: task.Metadata,
+			Result: taskResult[T]{
+				Value: zero,
+				Err:   fmt.Errorf("task not started: %w", err),
+			},
+		}
+	}
+
+	var result taskResult[T]
+	func() {
+		// Recover panic and convert into error.
+		defer func() {
+			if rec := recover(); rec != nil {
+				result = taskResult[T]{
+					Value: zero,
+					Err:   fmt.Errorf("panic: %v", rec),
+				}
+			}
+		}()
+
+		v, err := task.Run()
+		result = taskResult[T]{
+			Value: v,
+			Err:   err,
+		}
+	}()
+
+	return TaskExecution[T, M]{
+		Metadata: task.Metadata,
+		Result:   result,
+	}
+}
@@ -1,7 +1,9 @@
 package concurrent_test
 
 import (
+	"context"
 	"errors"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -199,3 +201,134 @@ func TestRunAllStream_EmptyTasks(t *testing.T) {
 		t.Fatalf("expected 0 results, got %d", count)
 	}
 }
+
+func TestRunAllContext_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var ran atomic.Int32
+	tasks := []concurrent.Task[int, string]{
+		{
+			Metadata: "task-1",
+			Run: func() (int, error) {
+				ran.Add(1)
+				return 1, nil
+			},
+		},
+		{
+			Metadata: "task-2",
+			Run: func() (int, error) {
+				ran.Add(1)
+				return 2, nil
+			},
+		},
+	}
+
+	results := concurrent.RunAllContext(ctx, tasks, concurrent.WithMaxConcurrency(1))
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	for _, result := range results {
+		if !errors.Is(result.Result.Err, context.Canceled) {
+			t.Fatalf("expected context.Canceled for %s, got: %+v", result.Metadata, result.Result)
+		}
+	}
+	if n := ran.Load(); n != 0 {
+		t.Fatalf("expected no task to run, but %d ran", n)
+	}
+}
+
+func TestRunAllStreamContext_CancelUnblocksWaiters(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	release := make(chan struct{})
+	tasks := make([]concurrent.Task[int, int], 0, 6)
+	for i := range 6 {
+		tasks = append(tasks, concurrent.Task[int, int]{
+			Metadata: i,
+			Run: func() (int, error) {
+				// Whichever task runs first cancels the others while holding the only slot.
+				cancel()
+				<-release
+				return i, nil
+			},
+		})
+	}
+
+	resultChan := concurrent.RunAllStreamContext(ctx, tasks, concurrent.WithMaxConcurrency(1))
+
+	// The waiting tasks must be reported before the running task is released.
+	for range 5 {
+		result := <-resultChan
+		if !errors.Is(result.Result.Err, context.Canceled) {
+			t.Fatalf("expected context.Canceled for waiting task, got: %+v", result)
+		}
+	}
+
+	close(release)
+	result := <-resultChan
+	if result.Result.Err != nil || result.Result.Value != result.Metadata {
+		t.Fatalf("expected running task to finish, got: %+v", result)
+	}
+	if _, ok := <-resultChan; ok {
+		t.Fatal("expected channel to be closed")
+	}
+}
+
+func TestRunSeqContext_AllTasks(t *testing.T) {
+	tasks := func(yield func(concurrent.Task[int, int]) bool) {
+		for i := range 5 {
+			if !yield(concurrent.Task[int, int]{Metadata: i, Run: func() (int, error) { return i * 10, nil }}) {
+				return
+			}
+		}
+	}
+
+	seen := make(map[int]bool)
+	for result := range concurrent.RunSeqContext(context.Background(), tasks, concurrent.WithMaxConcurrency(2)) {
+		if result.Result.Err != nil || result.Result.Value != result.Metadata*10 {
+			t.Fatalf("unexpected result: %+v", result)
+		}
+		seen[result.Metadata] = true
+	}
+	if len(seen) != 5 {
+		t.Fatalf("expected 5 distinct results, got %d", len(seen))
+	}
+}
+
+func TestRunSeqContext_Backpressure(t *testing.T) {
+	var pulled, ran atomic.Int32
+	tasks := func(yield func(concurrent.Task[int, int]) bool) {
+		for i := range 10 {
+			pulled.Add(1)
+			task := concurrent.Task[int, int]{
+				Metadata: i,
+				Run: func() (int, error) {
+					ran.Add(1)
+					return i, nil
+				},
+			}
+			if !yield(task) {
+				return
+			}
+		}
+	}
+
+	var count int
+	for range concurrent.RunSeqContext(context.Background(), tasks, concurrent.WithMaxConcurrency(3)) {
+		count++
+		if count == 2 {
+			break
+		}
+	}
+
+	// Three tasks are started up front, and one more once the first result has been consumed.
+	if n := pulled.Load(); n != 4 {
+		t.Fatalf("expected 4 tasks to be pulled, got %d", n)
+	}
+	if n := ran.Load(); n > 4 {
+		t.Fatalf("expected at most 4 tasks to run, but %d ran", n)
+	}
+}
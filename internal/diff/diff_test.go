@@ -0,0 +1,104 @@
+package diff_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sushichan044/mdfm/internal/diff"
+)
+
+func TestUnified(t *testing.T) {
+	lines := func(s ...string) []byte { return []byte(strings.Join(s, "\n") + "\n") }
+
+	tests := []struct {
+		name     string
+		a, b     []byte
+		expected string
+	}{
+		{
+			name: "equal",
+			a:    lines("a", "b"),
+			b:    lines("a", "b"),
+		},
+		{
+			name: "change with context",
+			a:    lines("---", "title: Hello", "draft: true", "---", "1", "2", "3", "4"),
+			b:    lines("---", "title: Hello", "draft: false", "---", "1", "2", "3", "4"),
+			expected: `--- a/post.md
++++ b/post.md
+@@ -1,6 +1,6 @@
+ ---
+ title: Hello
+-draft: true
++draft: false
+ ---
+ 1
+ 2
+`,
+		},
+		{
+			name: "insertion at the start",
+			a:    lines("# Body"),
+			b:    lines("---", "title: New", "---", "# Body"),
+			expected: `--- a/post.md
++++ b/post.md
+@@ -1 +1,4 @@
++---
++title: New
++---
+ # Body
+`,
+		},
+		{
+			name: "distant changes make separate hunks",
+			a:    lines("a", "1", "2", "3", "4", "5", "6", "7", "b"),
+			b:    lines("A", "1", "2", "3", "4", "5", "6", "7", "B"),
+			expected: `--- a/post.md
++++ b/post.md
+@@ -1,4 +1,4 @@
+-a
++A
+ 1
+ 2
+ 3
+@@ -6,4 +6,4 @@
+ 5
+ 6
+ 7
+-b
++B
+`,
+		},
+		{
+			name: "removal of the only line",
+			a:    lines("a"),
+			b:    nil,
+			expected: `--- a/post.md
++++ b/post.md
+@@ -1 +0,0 @@
+-a
+`,
+		},
+		{
+			name: "missing final newline",
+			a:    []byte("a\nb"),
+			b:    []byte("a\nc"),
+			expected: `--- a/post.md
++++ b/post.md
+@@ -1,2 +1,2 @@
+ a
+-b
+\ No newline at end of file
++c
+\ No newline at end of file
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, diff.Unified("a/post.md", "b/post.md", tt.a, tt.b))
+		})
+	}
+}
@@ -0,0 +1,329 @@
+package expr
+
+import (
+	"cmp"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bmatcuk/doublestar/v4"
+
+	"github.com/sushichan044/mdfm/internal/tree"
+)
+
+// Env holds the data an expression is evaluated against.
+type Env struct {
+	// FrontMatter is the frontmatter tree keys are looked up in, usually a *tree.OrderedMap.
+	FrontMatter any
+
+	// Vars are the values of the variables, keyed by name without the "$" prefix.
+	Vars map[string]any
+}
+
+// Eval evaluates the expression. Datetimes are returned as strings formatted by tree.FormatTime.
+func (e *Expr) Eval(env Env) any {
+	return e.root.eval(&env)
+}
+
+// Match reports whether the expression evaluates to a truthy value.
+func (e *Expr) Match(env Env) bool {
+	return truthy(e.Eval(env))
+}
+
+type node interface {
+	eval(env *Env) any
+}
+
+type (
+	literalNode struct{ value any }
+	keyNode     struct{ name string }
+	varNode     struct{ name string }
+	listNode    []node
+	indexNode   struct{ base, index node }
+	callNode    struct {
+		name string
+		args []node
+	}
+	notNode struct{ operand node }
+	negNode struct{ operand node }
+	andNode struct{ left, right node }
+	orNode  struct{ left, right node }
+
+	compareNode struct {
+		op          string
+		left, right node
+	}
+
+	matchNode struct {
+		left, right node
+		negate      bool
+		// re is the compiled pattern when right is a literal.
+		re *regexp.Regexp
+	}
+)
+
+func (n literalNode) eval(*Env) any { return n.value }
+
+func (n keyNode) eval(env *Env) any { return index(scalar(env.FrontMatter), n.name) }
+
+func (n varNode) eval(env *Env) any { return scalar(env.Vars[n.name]) }
+
+func (n listNode) eval(env *Env) any {
+	items := make([]any, len(n))
+	for i, item := range n {
+		items[i] = item.eval(env)
+	}
+	return items
+}
+
+func (n indexNode) eval(env *Env) any { return index(n.base.eval(env), n.index.eval(env)) }
+
+func (n notNode) eval(env *Env) any { return !truthy(n.operand.eval(env)) }
+
+func (n negNode) eval(env *Env) any {
+	switch v := n.operand.eval(env).(type) {
+	case int64:
+		return -v
+	case float64:
+		return -v
+	default:
+		return nil
+	}
+}
+
+func (n andNode) eval(env *Env) any { return truthy(n.left.eval(env)) && truthy(n.right.eval(env)) }
+
+func (n orNode) eval(env *Env) any { return truthy(n.left.eval(env)) || truthy(n.right.eval(env)) }
+
+func (n compareNode) eval(env *Env) any {
+	left, right := n.left.eval(env), n.right.eval(env)
+	switch n.op {
+	case "==":
+		return equal(left, right)
+	case "!=":
+		return !equal(left, right)
+	case "in":
+		return contains(right, left)
+	case "not in":
+		return !contains(right, left)
+	}
+
+	c, ok := order(left, right)
+	if !ok {
+		return false
+	}
+	switch n.op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default:
+		return c >= 0
+	}
+}
+
+func (n matchNode) eval(env *Env) any {
+	s, ok := n.left.eval(env).(string)
+	if !ok {
+		return false
+	}
+	re := n.re
+	if re == nil {
+		pattern, isString := n.right.eval(env).(string)
+		if !isString {
+			return false
+		}
+		var err error
+		if re, err = regexp.Compile(pattern); err != nil {
+			return false
+		}
+	}
+	return re.MatchString(s) != n.negate
+}
+
+func (n callNode) eval(env *Env) any {
+	args := make([]any, len(n.args))
+	for i, arg := range n.args {
+		args[i] = arg.eval(env)
+	}
+
+	switch n.name {
+	case "len":
+		switch v := args[0].(type) {
+		case string:
+			return int64(utf8.RuneCountInString(v))
+		case []any:
+			return int64(len(v))
+		case *tree.OrderedMap:
+			return int64(v.Len())
+		case map[string]any:
+			return int64(len(v))
+		}
+		return nil
+	case "lower", "upper":
+		s, ok := args[0].(string)
+		if !ok {
+			return nil
+		}
+		if n.name == "lower" {
+			return strings.ToLower(s)
+		}
+		return strings.ToUpper(s)
+	}
+
+	s, ok := args[0].(string)
+	arg, isString := args[1].(string)
+	if !ok || !isString {
+		return false
+	}
+	switch n.name {
+	case "startsWith":
+		return strings.HasPrefix(s, arg)
+	case "endsWith":
+		return strings.HasSuffix(s, arg)
+	default: // glob
+		matched, err := doublestar.Match(arg, s)
+		return err == nil && matched
+	}
+}
+
+// truthy reports whether v is neither null nor false.
+func truthy(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	default:
+		return true
+	}
+}
+
+// scalar converts datetimes to strings and integers to int64, the representations
+// the operators work with.
+func scalar(v any) any {
+	switch v := v.(type) {
+	case time.Time:
+		return tree.FormatTime(v)
+	case int:
+		return int64(v)
+	case tree.OrderedMap:
+		return &v
+	default:
+		return v
+	}
+}
+
+// index returns the value of key in a mapping or the item at an index of a sequence,
+// counting from the end for negative indexes. It returns nil if there is no such value.
+func index(base, key any) any {
+	switch base := base.(type) {
+	case *tree.OrderedMap:
+		if key, ok := key.(string); ok {
+			v, _ := base.Get(key)
+			return scalar(v)
+		}
+	case map[string]any:
+		if key, ok := key.(string); ok {
+			return scalar(base[key])
+		}
+	case []any:
+		i, ok := key.(int64)
+		if !ok {
+			return nil
+		}
+		if i < 0 {
+			i += int64(len(base))
+		}
+		if i >= 0 && i < int64(len(base)) {
+			return scalar(base[i])
+		}
+	}
+	return nil
+}
+
+// equal reports whether a and b are equal. Integers equal floats with the same value and
+// strings holding dates or datetimes equal those holding the same instant.
+func equal(a, b any) bool {
+	if s, ok := a.(string); ok {
+		if other, isString := b.(string); isString {
+			if c, isTime := compareTimes(s, other); isTime {
+				return c == 0
+			}
+			return s == other
+		}
+	}
+	return tree.Equal(a, b)
+}
+
+// contains reports whether item is an element of a list, a substring of a string or a key of a mapping.
+func contains(container, item any) bool {
+	switch container := container.(type) {
+	case []any:
+		for _, element := range container {
+			if equal(scalar(element), item) {
+				return true
+			}
+		}
+	case string:
+		s, ok := item.(string)
+		return ok && strings.Contains(container, s)
+	case *tree.OrderedMap:
+		if key, ok := item.(string); ok {
+			_, found := container.Get(key)
+			return found
+		}
+	case map[string]any:
+		if key, ok := item.(string); ok {
+			_, found := container[key]
+			return found
+		}
+	}
+	return false
+}
+
+// order compares two numbers, or two strings as instants if both are dates or datetimes and
+// lexicographically otherwise. It returns false if a and b cannot be ordered.
+func order(a, b any) (int, bool) {
+	switch a := a.(type) {
+	case int64:
+		switch b := b.(type) {
+		case int64:
+			return cmp.Compare(a, b), true
+		case float64:
+			return cmp.Compare(float64(a), b), true
+		}
+	case float64:
+		switch b := b.(type) {
+		case int64:
+			return cmp.Compare(a, float64(b)), true
+		case float64:
+			return cmp.Compare(a, b), true
+		}
+	case string:
+		b, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		if c, isTime := compareTimes(a, b); isTime {
+			return c, true
+		}
+		return strings.Compare(a, b), true
+	}
+	return 0, false
+}
+
+func compareTimes(a, b string) (int, bool) {
+	ta, ok := tree.ParseTime(a)
+	if !ok {
+		return 0, false
+	}
+	tb, ok := tree.ParseTime(b)
+	if !ok {
+		return 0, false
+	}
+	return ta.Compare(tb), true
+}
@@ -0,0 +1,176 @@
+package expr_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/mdfm/internal/expr"
+	"github.com/sushichan044/mdfm/internal/tree"
+)
+
+func testEnv(t *testing.T) expr.Env {
+	t.Helper()
+
+	var om tree.OrderedMap
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"title": "Hello, World",
+		"draft": false,
+		"tags": ["go", "web"],
+		"weight": 3,
+		"rating": 4.5,
+		"date": "2024-03-01",
+		"seo": {"description": "A post", "og:image": "cover.png"},
+		"allowed-tools": ["Bash"],
+		"note": null
+	}`), &om))
+	om.Set("published", time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC))
+
+	return expr.Env{
+		FrontMatter: &om,
+		Vars:        map[string]any{"path": "posts/hello.md", "size": int64(120)},
+	}
+}
+
+func TestExpr_Match(t *testing.T) {
+	env := testEnv(t)
+	vars := []string{"path", "size"}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`draft == false && "go" in tags && date >= "2024-01-01"`, true},
+		{`draft`, false},
+		{`!draft`, true},
+		{`title`, true},
+		{`missing`, false},
+		{`missing == null`, true},
+		{`note == null && note != false`, true},
+		{`weight == 3 && weight == 3.0 && weight < rating`, true},
+		{`weight > -1 && -weight < 0`, true},
+		{`rating >= 4.5 && rating < 5`, true},
+		{`weight > "2"`, false},
+		{`title == 'Hello, World'`, true},
+		{`title < "Z" && title > "A"`, true},
+		{`"World" in title && "world" not in title`, true},
+		{`"rust" in tags || "web" in tags`, true},
+		{`"rust" not in tags`, true},
+		{`"description" in seo && "title" not in seo`, true},
+		{`tags == ["go", "web"] && tags != ["go"]`, true},
+		{`tags[0] == "go" && tags[-1] == "web" && tags[2] == null`, true},
+		{`seo.description == "A post" && seo["og:image"] == "cover.png"`, true},
+		{"`allowed-tools`[0] == \"Bash\"", true},
+		{`missing.deeper[0] == null`, true},
+		{`date == "2024-03-01T00:00:00Z" && date < "2024-03-01T00:00:01Z"`, true},
+		{`published > "2024-03-01" && published == "2024-03-01T10:00:00Z"`, true},
+		{`weight in [1, 2, 3]`, true},
+		{`title =~ "^Hello" && title !~ "^Bye"`, true},
+		{`weight =~ "3"`, false},
+		{`len(tags) == 2 && len(title) == 12 && len(seo) == 2 && len(weight) == null`, true},
+		{`lower(title) == "hello, world" && upper(tags[0]) == "GO"`, true},
+		{`startsWith($path, "posts/") && endsWith($path, ".md") && !startsWith(weight, "3")`, true},
+		{`glob($path, "posts/**") && !glob($path, "drafts/**")`, true},
+		{`$size > 100 && $size <= 120`, true},
+		{`(draft || weight > 5) && true`, false},
+		{`draft || (weight > 1 && rating > 4)`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := expr.Parse(tt.expr, vars)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, e.Match(env))
+		})
+	}
+}
+
+func TestExpr_Eval(t *testing.T) {
+	env := testEnv(t)
+
+	tests := []struct {
+		expr string
+		want any
+	}{
+		{`title`, "Hello, World"},
+		{`weight`, int64(3)},
+		{`rating`, 4.5},
+		{`published`, "2024-03-01T10:00:00Z"},
+		{`tags`, []any{"go", "web"}},
+		{`[1, "a", null]`, []any{int64(1), "a", nil}},
+		{`"tab\tand \"quotes\""`, "tab\tand \"quotes\""},
+		{`'it\'s "fine"'`, `it's "fine"`},
+		{`1.5e2`, 150.0},
+		{`-rating`, -4.5},
+		{`-title`, nil},
+		{`$path`, "posts/hello.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := expr.Parse(tt.expr, []string{"path"})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, e.Eval(env))
+		})
+	}
+}
+
+// TestExpr_TOMLLocalTimes checks that TOML local dates and times compare by their wall clock,
+// whatever the time zone of the machine that decoded them.
+func TestExpr_TOMLLocalTimes(t *testing.T) {
+	var om tree.OrderedMap
+	_, err := toml.Decode("date = 2024-03-01\nlocal = 2024-03-01T10:00:00\nat = 10:30:00\n", &om)
+	require.NoError(t, err)
+	env := expr.Env{FrontMatter: &om}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`date == "2024-03-01" && date <= "2024-03-01" && date >= "2024-03-01"`, true},
+		{`date > "2024-02-29" && date < "2024-03-02"`, true},
+		{`date == "2024-03-01T00:00:00Z"`, true},
+		{`local == "2024-03-01T10:00:00" && local > "2024-03-01" && local < "2024-03-01T10:00:01"`, true},
+		{`at == "10:30:00"`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := expr.Parse(tt.expr, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, e.Match(env))
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{``, `column 1: expected a value, found end of expression`},
+		{`draft ==`, `column 9: expected a value, found end of expression`},
+		{`draft = false`, `column 7: unexpected character '='`},
+		{`(draft`, `column 7: expected ")", found end of expression`},
+		{`draft false`, `column 7: expected end of expression, found "false"`},
+		{`"go" not tags`, `column 10: expected "in", found "tags"`},
+		{`title == "open`, `column 10: unterminated string`},
+		{"`open", `column 1: unterminated quoted key`},
+		{`$path == "a"`, `column 1: unknown variable $path (want one of $size)`},
+		{`$ == 1`, `column 1: expected a variable name after $`},
+		{`size(tags)`, `column 1: unknown function size`},
+		{`len(tags, title)`, `column 1: len expects 1 arguments, got 2`},
+		{`title =~ "("`, "column 7: invalid regular expression: error parsing regexp: missing closing ): `(`"},
+		{`title =~ 1`, `column 7: =~ expects a string pattern`},
+		{`seo.1`, `column 5: expected key, found "1"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := expr.Parse(tt.expr, []string{"size"})
+			var syntaxErr *expr.SyntaxError
+			require.ErrorAs(t, err, &syntaxErr)
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package expr
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokQuotedIdent
+	tokVar
+	tokString
+	tokNumber
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	// text is the identifier, the variable name without "$", the unquoted string or the operator.
+	text   string
+	offset int
+}
+
+// operators are sorted so that longer operators are matched first.
+//
+//nolint:gochecknoglobals // Read-only lookup table.
+var operators = []string{
+	"==", "!=", "<=", ">=", "&&", "||", "=~", "!~",
+	"<", ">", "!", "-", "(", ")", "[", "]", ",", ".",
+}
+
+func lex(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		r, size := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '"' || r == '\'' || r == '`':
+			tok, end, err := lexQuoted(src, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			i = end
+		case r == '$':
+			end := identEnd(src, i+1)
+			if end == i+1 {
+				return nil, &SyntaxError{Column: i + 1, Msg: "expected a variable name after $"}
+			}
+			tokens = append(tokens, token{kind: tokVar, text: src[i+1 : end], offset: i})
+			i = end
+		case r >= '0' && r <= '9':
+			end := numberEnd(src, i)
+			tokens = append(tokens, token{kind: tokNumber, text: src[i:end], offset: i})
+			i = end
+		case isIdentStart(r):
+			end := identEnd(src, i)
+			tokens = append(tokens, token{kind: tokIdent, text: src[i:end], offset: i})
+			i = end
+		default:
+			op := lexOperator(src[i:])
+			if op == "" {
+				return nil, &SyntaxError{Column: i + 1, Msg: "unexpected character " + strconv.QuoteRune(r)}
+			}
+			tokens = append(tokens, token{kind: tokOp, text: op, offset: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{kind: tokEOF, offset: len(src)}), nil
+}
+
+// lexQuoted lexes the string or backquoted key starting at offset start, and returns the
+// offset right after it.
+func lexQuoted(src string, start int) (token, int, error) {
+	quote := src[start]
+	end := start + 1
+	for end < len(src) && src[end] != quote {
+		if src[end] == '\\' && quote != '`' {
+			end++
+		}
+		end++
+	}
+	if end >= len(src) {
+		return token{}, 0, &SyntaxError{Column: start + 1, Msg: "unterminated " + quoteName(quote)}
+	}
+	end++
+
+	raw := src[start:end]
+	if quote == '`' {
+		return token{kind: tokQuotedIdent, text: raw[1 : len(raw)-1], offset: start}, end, nil
+	}
+	if quote == '\'' {
+		// Single-quoted strings use the same escapes as double-quoted ones.
+		raw = `"` + strings.ReplaceAll(strings.ReplaceAll(raw[1:len(raw)-1], `\'`, `'`), `"`, `\"`) + `"`
+	}
+	text, err := strconv.Unquote(raw)
+	if err != nil {
+		return token{}, 0, &SyntaxError{Column: start + 1, Msg: "invalid string " + src[start:end]}
+	}
+	return token{kind: tokString, text: text, offset: start}, end, nil
+}
+
+func quoteName(quote byte) string {
+	if quote == '`' {
+		return "quoted key"
+	}
+	return "string"
+}
+
+func lexOperator(src string) string {
+	for _, op := range operators {
+		if strings.HasPrefix(src, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func identEnd(src string, start int) int {
+	end := start
+	for end < len(src) {
+		r, size := utf8.DecodeRuneInString(src[end:])
+		if !isIdentStart(r) && !unicode.IsDigit(r) {
+			break
+		}
+		end += size
+	}
+	return end
+}
+
+func numberEnd(src string, start int) int {
+	end := start
+	for end < len(src) {
+		c := src[end]
+		switch {
+		case c >= '0' && c <= '9':
+		case c == '.' && end+1 < len(src) && src[end+1] >= '0' && src[end+1] <= '9':
+		case (c == 'e' || c == 'E') && end+1 < len(src):
+			if next := src[end+1]; next == '+' || next == '-' {
+				end++
+			}
+		default:
+			return end
+		}
+		end++
+	}
+	return end
+}
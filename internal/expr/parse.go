@@ -0,0 +1,335 @@
+// Package expr implements the small expression language used to filter documents by their
+// frontmatter, e.g. `draft == false && "go" in tags && date >= "2024-01-01"`.
+//
+// Expressions are made of:
+//
+//   - literals: strings ("go" or 'go'), numbers (3, 1.5), true, false, null and lists ([1, 2]),
+//   - frontmatter keys: title, seo.description, tags[0], or `allowed-tools` for keys that are
+//     not identifiers; missing keys are null,
+//   - variables, such as $path, whose names are given to Parse,
+//   - the operators ||, &&, !, ==, !=, <, <=, >, >=, in, not in, =~ (regular expression match)
+//     and !~, and unary minus,
+//   - the functions len, lower, upper, startsWith, endsWith and glob.
+//
+// Values are never an error at evaluation time: operators applied to values of the wrong types
+// evaluate to false or null. null and false are falsy, every other value is truthy.
+package expr
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SyntaxError is returned by Parse for invalid expressions.
+type SyntaxError struct {
+	// Column is the 1-based column, counted in bytes, where the problem was found.
+	Column int
+
+	// Msg describes the problem.
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Column, e.Msg)
+}
+
+// Expr is a parsed expression.
+type Expr struct {
+	root node
+}
+
+// functions are the functions that can be called in expressions, with their number of arguments.
+//
+//nolint:gochecknoglobals // Read-only lookup table.
+var functions = map[string]int{
+	"len":        1,
+	"lower":      1,
+	"upper":      1,
+	"startsWith": 2,
+	"endsWith":   2,
+	"glob":       2,
+}
+
+// Parse parses an expression. vars are the names of the variables the expression may refer to,
+// without their "$" prefix.
+func Parse(src string, vars []string) (*Expr, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, vars: vars}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.unexpected(tok, "end of expression")
+	}
+	return &Expr{root: root}, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	vars   []string
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept consumes the next token if it is the operator or keyword op.
+func (p *parser) accept(op string) bool {
+	if tok := p.peek(); (tok.kind == tokOp || tok.kind == tokIdent) && tok.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(op string) error {
+	if !p.accept(op) {
+		return p.unexpected(p.peek(), strconv.Quote(op))
+	}
+	return nil
+}
+
+func (p *parser) unexpected(tok token, want string) error {
+	found := strconv.Quote(tok.text)
+	if tok.kind == tokEOF {
+		found = "end of expression"
+	}
+	return &SyntaxError{Column: tok.offset + 1, Msg: fmt.Sprintf("expected %s, found %s", want, found)}
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	tok := p.peek()
+	op := tok.text
+	switch {
+	case tok.kind == tokOp && slices.Contains([]string{"==", "!=", "<", "<=", ">", ">=", "=~", "!~"}, op):
+		p.next()
+	case tok.kind == tokIdent && op == "in":
+		p.next()
+	case tok.kind == tokIdent && op == "not":
+		p.next()
+		if err = p.expect("in"); err != nil {
+			return nil, err
+		}
+		op = "not in"
+	default:
+		return left, nil
+	}
+
+	right, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	if op == "=~" || op == "!~" {
+		return newMatchNode(left, right, op == "!~", tok)
+	}
+	return compareNode{op: op, left: left, right: right}, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	switch {
+	case p.accept("!"):
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand}, nil
+	case p.accept("-"):
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return negNode{operand}, nil
+	default:
+		return p.parsePostfix()
+	}
+}
+
+func (p *parser) parsePostfix() (node, error) {
+	n, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.accept("."):
+			tok := p.next()
+			if tok.kind != tokIdent && tok.kind != tokQuotedIdent {
+				return nil, p.unexpected(tok, "key")
+			}
+			n = indexNode{n, literalNode{tok.text}}
+		case p.accept("["):
+			index, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err = p.expect("]"); err != nil {
+				return nil, err
+			}
+			n = indexNode{n, index}
+		default:
+			return n, nil
+		}
+	}
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokString:
+		return literalNode{tok.text}, nil
+	case tokNumber:
+		if i, err := strconv.ParseInt(tok.text, 10, 64); err == nil {
+			return literalNode{i}, nil
+		}
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, &SyntaxError{Column: tok.offset + 1, Msg: "invalid number " + tok.text}
+		}
+		return literalNode{f}, nil
+	case tokVar:
+		if !slices.Contains(p.vars, tok.text) {
+			return nil, &SyntaxError{Column: tok.offset + 1, Msg: fmt.Sprintf("unknown variable $%s (want one of $%s)", tok.text, strings.Join(p.vars, ", $"))}
+		}
+		return varNode{tok.text}, nil
+	case tokQuotedIdent:
+		return keyNode{tok.text}, nil
+	case tokIdent:
+		return p.parseIdent(tok)
+	case tokOp:
+		switch tok.text {
+		case "(":
+			n, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			return n, p.expect(")")
+		case "[":
+			return p.parseList()
+		}
+	case tokEOF:
+	}
+	return nil, p.unexpected(tok, "a value")
+}
+
+func (p *parser) parseIdent(tok token) (node, error) {
+	switch tok.text {
+	case "true":
+		return literalNode{true}, nil
+	case "false":
+		return literalNode{false}, nil
+	case "null":
+		return literalNode{nil}, nil
+	}
+
+	if !p.accept("(") {
+		return keyNode{tok.text}, nil
+	}
+	arity, ok := functions[tok.text]
+	if !ok {
+		return nil, &SyntaxError{Column: tok.offset + 1, Msg: "unknown function " + tok.text}
+	}
+	var args []node
+	for !p.accept(")") {
+		if len(args) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	if len(args) != arity {
+		return nil, &SyntaxError{Column: tok.offset + 1, Msg: fmt.Sprintf("%s expects %d arguments, got %d", tok.text, arity, len(args))}
+	}
+	return callNode{name: tok.text, args: args}, nil
+}
+
+func (p *parser) parseList() (node, error) {
+	var items listNode
+	for !p.accept("]") {
+		if len(items) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		item, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// newMatchNode returns a node that matches left against the regular expression right,
+// which is compiled once if it is a literal.
+func newMatchNode(left, right node, negate bool, tok token) (node, error) {
+	n := matchNode{left: left, right: right, negate: negate}
+	if lit, ok := right.(literalNode); ok {
+		pattern, isString := lit.value.(string)
+		if !isString {
+			return nil, &SyntaxError{Column: tok.offset + 1, Msg: tok.text + " expects a string pattern"}
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, &SyntaxError{Column: tok.offset + 1, Msg: "invalid regular expression: " + err.Error()}
+		}
+		n.re = re
+	}
+	return n, nil
+}
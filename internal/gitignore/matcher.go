@@ -2,7 +2,9 @@ package gitignore
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 
@@ -29,7 +31,7 @@ func New(root string) (*Matcher, error) {
 		giPaths = append(giPaths, globalGi)
 	}
 
-	if localGi, err := getLocalGitIgnorePath(); err != nil {
+	if localGi, err := getLocalGitIgnorePath(root); err != nil {
 		return nil, fmt.Errorf("failed to get local gitignore path: %w", err)
 	} else if localGi != "" {
 		giPaths = append(giPaths, localGi)
@@ -73,3 +75,30 @@ func (m *Matcher) IsIgnored(path string) bool {
 	rel = filepath.ToSlash(rel)
 	return m.matcher.MatchesPath(rel)
 }
+
+// NewFS creates a Matcher from the `.gitignore` file at the root of fsys.
+// Global and repository-local Git excludes are not consulted, since fsys is not
+// necessarily backed by a Git working tree. Paths passed to IsIgnored must be
+// slash-separated and relative to the root of fsys.
+// If no .gitignore exists, it returns a matcher that never matches.
+func NewFS(fsys fs.FS) (*Matcher, error) {
+	file, err := fsys.Open(".gitignore")
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Matcher{matcher: ignore.CompileIgnoreLines()}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open .gitignore: %w", err)
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if sErr := scanner.Err(); sErr != nil {
+		return nil, fmt.Errorf("failed to read .gitignore: %w", sErr)
+	}
+
+	return &Matcher{matcher: ignore.CompileIgnoreLines(lines...)}, nil
+}
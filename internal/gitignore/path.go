@@ -38,12 +38,10 @@ func getDefaultExcludesFilePath() (string, error) {
 	return filepath.Join(home, ".config", "git", "ignore"), nil
 }
 
-func getLocalGitIgnorePath() (string, error) {
+func getLocalGitIgnorePath(root string) (string, error) {
 	gitDir := os.Getenv("GIT_DIR")
-	if gitDir == "" {
-		if wd, err := os.Getwd(); err == nil {
-			gitDir = filepath.Join(wd, ".git")
-		}
+	if gitDir == "" && root != "" {
+		gitDir = filepath.Join(root, ".git")
 	}
 
 	if gitDir == "" {
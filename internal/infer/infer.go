@@ -0,0 +1,292 @@
+// Package infer infers the shape of frontmatter from the documents of a corpus.
+package infer
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/sushichan044/mdfm/internal/tree"
+)
+
+// Kind is the type of an observed value. Datetimes are told apart from other strings,
+// whether the format has a datetime type (YAML, TOML) or they are strings in RFC 3339
+// form, so that they get the same kind in every format.
+type Kind string
+
+// Kinds of values.
+const (
+	KindString   Kind = "string"
+	KindInteger  Kind = "integer"
+	KindNumber   Kind = "number"
+	KindBoolean  Kind = "boolean"
+	KindNull     Kind = "null"
+	KindArray    Kind = "array"
+	KindObject   Kind = "object"
+	KindDatetime Kind = "datetime"
+	KindDate     Kind = "date"
+	KindTime     Kind = "time"
+)
+
+// maxExamples is the number of distinct example values kept for each field.
+const maxExamples = 3
+
+// ItemsName is the name of the Field that describes the items of sequences.
+const ItemsName = "[]"
+
+// Field describes the values observed for a frontmatter key, or for the items of a sequence.
+type Field struct {
+	// Name is the key, or ItemsName for the items of a sequence.
+	Name string
+
+	// Path is the path of the key from the root, joining keys with "." and marking the items
+	// of sequences with "[]", e.g. "seo.description" or "authors[].name". It is empty for the root.
+	Path string
+
+	// Files is the number of documents in which the key appears.
+	Files int
+
+	// Kinds is the number of documents in which the key has a value of each kind.
+	Kinds map[Kind]int
+
+	// Examples are up to three distinct scalar values of the key, as returned by tree.JSONSafe.
+	Examples []any
+
+	// Items describes the items of the sequences observed for the key, if any.
+	Items *Field
+
+	properties map[string]*Field
+	// values is the number of values observed, and objects the number of mappings among them.
+	values, objects int
+}
+
+// Schema accumulates the fields of the frontmatter of a set of documents.
+// The zero value is not usable; use New.
+type Schema struct {
+	root *Field
+}
+
+// New returns a Schema that has not observed any document.
+func New() *Schema {
+	return &Schema{root: newField("", "")}
+}
+
+// Add records the frontmatter of one document, as decoded by the tree package.
+func (s *Schema) Add(frontMatter any) {
+	o := observation{files: map[*Field]bool{}, kinds: map[*Field]map[Kind]bool{}}
+	o.observe(s.root, frontMatter)
+}
+
+// Documents returns the number of documents added to s.
+func (s *Schema) Documents() int {
+	return s.root.Files
+}
+
+// Root returns the field that describes the frontmatter as a whole.
+func (s *Schema) Root() *Field {
+	return s.root
+}
+
+// Fields returns every field observed in s, depth-first with properties sorted by key.
+// The items of a sequence come right after the sequence.
+func (s *Schema) Fields() []*Field {
+	var fields []*Field
+	var walk func(f *Field)
+	walk = func(f *Field) {
+		if f != s.root {
+			fields = append(fields, f)
+		}
+		if f.Items != nil {
+			walk(f.Items)
+		}
+		for _, p := range f.Properties() {
+			walk(p)
+		}
+	}
+	walk(s.root)
+	return fields
+}
+
+// Properties returns the fields of the keys observed in the mappings of f, sorted by key.
+func (f *Field) Properties() []*Field {
+	props := slices.Collect(maps.Values(f.properties))
+	slices.SortFunc(props, func(a, b *Field) int { return cmp.Compare(a.Name, b.Name) })
+	return props
+}
+
+// SortedKinds returns the kinds observed for f, the most frequent first.
+func (f *Field) SortedKinds() []Kind {
+	kinds := slices.Collect(maps.Keys(f.Kinds))
+	slices.SortFunc(kinds, func(a, b Kind) int {
+		return cmp.Or(cmp.Compare(f.Kinds[b], f.Kinds[a]), cmp.Compare(a, b))
+	})
+	return kinds
+}
+
+// Conflict reports whether f has values of incompatible kinds, e.g. strings and sequences.
+// Null values are not conflicts, nor are integers mixed with other numbers, or dates mixed
+// with datetimes.
+func (f *Field) Conflict() bool {
+	return len(f.families()) > 1
+}
+
+// Kind returns the kind of all the values of f that are not null, and false if f has values
+// of conflicting kinds or only null values. Integers mixed with other numbers are KindNumber,
+// and dates mixed with datetimes KindDatetime.
+func (f *Field) Kind() (Kind, bool) {
+	families := f.families()
+	if len(families) != 1 {
+		return "", false
+	}
+	switch kind := families[0]; {
+	case kind == KindNumber && f.Kinds[KindNumber] == 0:
+		return KindInteger, true
+	case kind == KindDatetime && f.Kinds[KindDatetime] == 0:
+		return KindDate, true
+	default:
+		return kind, true
+	}
+}
+
+// Required reports whether every mapping observed for f has the key of prop.
+func (f *Field) Required(prop *Field) bool {
+	return prop.values == f.objects
+}
+
+// Nullable reports whether f was null in some documents.
+func (f *Field) Nullable() bool {
+	return f.Kinds[KindNull] > 0
+}
+
+// families returns the kinds of the values of f that are not null, counting integers as
+// numbers and dates as datetimes.
+func (f *Field) families() []Kind {
+	var families []Kind
+	for kind := range f.Kinds {
+		switch kind {
+		case KindNull:
+			continue
+		case KindInteger:
+			kind = KindNumber
+		case KindDate:
+			kind = KindDatetime
+		case KindString, KindNumber, KindBoolean, KindArray, KindObject, KindDatetime, KindTime:
+		}
+		if !slices.Contains(families, kind) {
+			families = append(families, kind)
+		}
+	}
+	return families
+}
+
+func newField(name, path string) *Field {
+	return &Field{Name: name, Path: path, Kinds: map[Kind]int{}, properties: map[string]*Field{}}
+}
+
+// observation records the values of a single document, so that the fields and kinds
+// observed several times in it are only counted once.
+type observation struct {
+	files map[*Field]bool
+	kinds map[*Field]map[Kind]bool
+}
+
+func (o observation) observe(f *Field, v any) {
+	f.values++
+	if !o.files[f] {
+		o.files[f] = true
+		f.Files++
+	}
+
+	kind := kindOf(v)
+	if o.kinds[f] == nil {
+		o.kinds[f] = map[Kind]bool{}
+	}
+	if !o.kinds[f][kind] {
+		o.kinds[f][kind] = true
+		f.Kinds[kind]++
+	}
+
+	switch v := v.(type) {
+	case *tree.OrderedMap:
+		f.objects++
+		for key, value := range v.All() {
+			o.observe(f.property(key), value)
+		}
+	case map[string]any:
+		f.objects++
+		for key, value := range v {
+			o.observe(f.property(key), value)
+		}
+	case []any:
+		for _, item := range v {
+			if f.Items == nil {
+				f.Items = newField(ItemsName, f.Path+ItemsName)
+			}
+			o.observe(f.Items, item)
+		}
+	default:
+		f.addExample(tree.JSONSafe(v))
+	}
+}
+
+func (f *Field) property(key string) *Field {
+	prop, ok := f.properties[key]
+	if !ok {
+		path := key
+		if f.Path != "" {
+			path = f.Path + "." + key
+		}
+		prop = newField(key, path)
+		f.properties[key] = prop
+	}
+	return prop
+}
+
+func (f *Field) addExample(v any) {
+	if v == nil || len(f.Examples) >= maxExamples || slices.Contains(f.Examples, v) {
+		return
+	}
+	f.Examples = append(f.Examples, v)
+}
+
+// kindOf returns the kind of a value of a tree.
+func kindOf(v any) Kind {
+	switch v := v.(type) {
+	case nil:
+		return KindNull
+	case bool:
+		return KindBoolean
+	case int64:
+		return KindInteger
+	case float64:
+		return KindNumber
+	case time.Time:
+		return timeKind(tree.FormatTime(v))
+	case string:
+		return timeKind(v)
+	case []any:
+		return KindArray
+	case *tree.OrderedMap, map[string]any:
+		return KindObject
+	default:
+		return KindString
+	}
+}
+
+// timeKind returns the kind of a string, telling dates and datetimes apart from other strings.
+func timeKind(s string) Kind {
+	if _, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return KindDatetime
+	}
+	if _, err := time.Parse("2006-01-02T15:04:05.999999999", s); err == nil {
+		return KindDatetime
+	}
+	if _, err := time.Parse(time.DateOnly, s); err == nil {
+		return KindDate
+	}
+	if _, err := time.Parse("15:04:05.999999999", s); err == nil {
+		return KindTime
+	}
+	return KindString
+}
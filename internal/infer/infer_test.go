@@ -0,0 +1,175 @@
+package infer_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/sushichan044/mdfm/internal/infer"
+	"github.com/sushichan044/mdfm/internal/tree"
+)
+
+func newSchema(t *testing.T, documents ...string) *infer.Schema {
+	t.Helper()
+
+	schema := infer.New()
+	for _, doc := range documents {
+		var om tree.OrderedMap
+		require.NoError(t, json.Unmarshal([]byte(doc), &om))
+		schema.Add(&om)
+	}
+	return schema
+}
+
+func TestSchema_Fields(t *testing.T) {
+	schema := newSchema(t,
+		`{"title": "A", "tags": ["go", "web"], "authors": [{"name": "x"}, {"name": "y", "email": "y@example.com"}]}`,
+		`{"title": "B", "tags": "solo", "date": "2024-01-02"}`,
+		`{"title": "C", "tags": ["go"], "date": "2024-01-03T10:00:00Z", "weight": 1, "seo": null}`,
+		`{"title": "D", "weight": 1.5, "seo": {"description": "d"}}`,
+	)
+	assert.Equal(t, 4, schema.Documents())
+
+	type row struct {
+		path     string
+		files    int
+		kinds    []infer.Kind
+		conflict bool
+		examples []any
+	}
+	var rows []row
+	for _, f := range schema.Fields() {
+		rows = append(rows, row{f.Path, f.Files, f.SortedKinds(), f.Conflict(), f.Examples})
+	}
+	assert.Equal(t, []row{
+		{"authors", 1, []infer.Kind{infer.KindArray}, false, nil},
+		{"authors[]", 1, []infer.Kind{infer.KindObject}, false, nil},
+		{"authors[].email", 1, []infer.Kind{infer.KindString}, false, []any{"y@example.com"}},
+		{"authors[].name", 1, []infer.Kind{infer.KindString}, false, []any{"x", "y"}},
+		{"date", 2, []infer.Kind{infer.KindDate, infer.KindDatetime}, false, []any{"2024-01-02", "2024-01-03T10:00:00Z"}},
+		{"seo", 2, []infer.Kind{infer.KindNull, infer.KindObject}, false, nil},
+		{"seo.description", 1, []infer.Kind{infer.KindString}, false, []any{"d"}},
+		{"tags", 3, []infer.Kind{infer.KindArray, infer.KindString}, true, []any{"solo"}},
+		{"tags[]", 2, []infer.Kind{infer.KindString}, false, []any{"go", "web"}},
+		{"title", 4, []infer.Kind{infer.KindString}, false, []any{"A", "B", "C"}},
+		{"weight", 2, []infer.Kind{infer.KindInteger, infer.KindNumber}, false, []any{int64(1), 1.5}},
+	}, rows)
+
+	root := schema.Root()
+	required := map[string]bool{}
+	for _, prop := range root.Properties() {
+		required[prop.Name] = root.Required(prop)
+	}
+	assert.Equal(t, map[string]bool{"authors": false, "date": false, "seo": false, "tags": false, "title": true, "weight": false}, required)
+
+	authors := root.Properties()[0].Items
+	props := authors.Properties()
+	assert.False(t, authors.Required(props[0]), "email is missing from one author")
+	assert.True(t, authors.Required(props[1]))
+}
+
+func TestSchema_Times(t *testing.T) {
+	schema := infer.New()
+	om := tree.NewOrderedMap()
+	om.Set("published", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	schema.Add(om)
+
+	published := schema.Fields()[0]
+	assert.Equal(t, []infer.Kind{infer.KindDatetime}, published.SortedKinds())
+	assert.Equal(t, []any{"2024-01-02T03:04:05Z"}, published.Examples)
+}
+
+func TestSchema_YAMLAndTOMLDates(t *testing.T) {
+	var fromYAML, fromTOML tree.OrderedMap
+	require.NoError(t, yaml.Unmarshal([]byte("date: 2024-01-02\n"), &fromYAML))
+	_, err := toml.Decode("date = 2024-01-03\nat = 10:30:00\nlocal = 2024-01-03T10:30:00\n", &fromTOML)
+	require.NoError(t, err)
+
+	schema := infer.New()
+	schema.Add(&fromYAML)
+	schema.Add(&fromTOML)
+
+	kinds := make(map[string][]infer.Kind)
+	examples := make(map[string][]any)
+	for _, field := range schema.Fields() {
+		kinds[field.Name] = field.SortedKinds()
+		examples[field.Name] = field.Examples
+	}
+	assert.Equal(t, []infer.Kind{infer.KindDate}, kinds["date"])
+	assert.Equal(t, []any{"2024-01-02", "2024-01-03"}, examples["date"])
+	assert.Equal(t, []infer.Kind{infer.KindTime}, kinds["at"])
+	assert.Equal(t, []infer.Kind{infer.KindDatetime}, kinds["local"])
+	assert.Equal(t, []any{"2024-01-03T10:30:00"}, examples["local"])
+}
+
+func TestSchema_JSONSchema(t *testing.T) {
+	schema := newSchema(t,
+		`{"title": "A", "tags": ["go"], "date": "2024-01-02", "seo": {"description": "d"}}`,
+		`{"title": "B", "tags": "solo", "draft": null, "weight": 1}`,
+		`{"title": "C", "weight": 1.5, "draft": true}`,
+	)
+
+	got, err := json.Marshal(schema.JSONSchema())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"date": {"type": "string", "format": "date", "examples": ["2024-01-02"]},
+			"draft": {"type": ["boolean", "null"], "examples": [true]},
+			"seo": {
+				"type": "object",
+				"properties": {"description": {"type": "string", "examples": ["d"]}},
+				"required": ["description"]
+			},
+			"tags": {
+				"anyOf": [
+					{"type": "array", "items": {"type": "string", "examples": ["go"]}},
+					{"type": "string"}
+				],
+				"examples": ["solo"]
+			},
+			"title": {"type": "string", "examples": ["A", "B", "C"]},
+			"weight": {"type": "number", "examples": [1, 1.5]}
+		},
+		"required": ["title"]
+	}`, string(got))
+}
+
+func TestSchema_Empty(t *testing.T) {
+	schema := infer.New()
+
+	assert.Empty(t, schema.Fields())
+	got, err := json.Marshal(schema.JSONSchema())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"$schema": "https://json-schema.org/draft/2020-12/schema", "type": "object"}`, string(got))
+}
+
+func TestField_Kind(t *testing.T) {
+	schema := newSchema(t,
+		`{"count": 1, "weight": 1, "date": "2024-01-02", "updated": "2024-01-02", "tags": ["a"], "note": null}`,
+		`{"count": 2, "weight": 1.5, "date": "2024-01-02", "updated": "2024-01-03T10:00:00Z", "tags": "a", "note": "n"}`,
+	)
+
+	kinds := map[string]infer.Kind{}
+	for _, prop := range schema.Root().Properties() {
+		kind, ok := prop.Kind()
+		if !ok {
+			kind = "conflict"
+		}
+		kinds[prop.Name] = kind
+	}
+	assert.Equal(t, map[string]infer.Kind{
+		"count":   infer.KindInteger,
+		"date":    infer.KindDate,
+		"note":    infer.KindString,
+		"tags":    "conflict",
+		"updated": infer.KindDatetime,
+		"weight":  infer.KindNumber,
+	}, kinds)
+}
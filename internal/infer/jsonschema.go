@@ -0,0 +1,126 @@
+package infer
+
+import (
+	"slices"
+
+	"github.com/sushichan044/mdfm/internal/tree"
+)
+
+// jsonSchemaDialect is the JSON Schema draft of the schemas returned by JSONSchema.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema returns a JSON Schema that the frontmatter of every document added to s matches,
+// in the JSON form the frontmatter takes in the output of the CLI. Keys that are present in
+// every mapping are required, and fields that have values of several kinds accept all of them.
+func (s *Schema) JSONSchema() *tree.OrderedMap {
+	schema := tree.NewOrderedMap()
+	schema.Set("$schema", jsonSchemaDialect)
+	for key, value := range objectSchema(s.root).All() {
+		schema.Set(key, value)
+	}
+	return schema
+}
+
+// fieldSchema returns the schema of the values of f.
+func fieldSchema(f *Field) *tree.OrderedMap {
+	kinds := f.SortedKinds()
+	if f.Kinds[KindNumber] > 0 {
+		// Integers are numbers too.
+		kinds = slices.DeleteFunc(kinds, func(k Kind) bool { return k == KindInteger })
+	}
+
+	var schemas []*tree.OrderedMap
+	simple := true
+	for _, kind := range kinds {
+		schema := kindSchema(f, kind)
+		if schema.Len() > 1 {
+			simple = false
+		}
+		schemas = append(schemas, schema)
+	}
+
+	var schema *tree.OrderedMap
+	switch {
+	case len(schemas) == 1:
+		schema = schemas[0]
+	case simple:
+		// Several plain types are written as {"type": ["string", "null"]}.
+		types := make([]any, len(schemas))
+		for i, s := range schemas {
+			types[i], _ = s.Get("type")
+		}
+		schema = tree.NewOrderedMap()
+		schema.Set("type", types)
+	default:
+		anyOf := make([]any, len(schemas))
+		for i, s := range schemas {
+			anyOf[i] = s
+		}
+		schema = tree.NewOrderedMap()
+		schema.Set("anyOf", anyOf)
+	}
+
+	if len(f.Examples) > 0 {
+		schema.Set("examples", append([]any(nil), f.Examples...))
+	}
+	return schema
+}
+
+// kindSchema returns the schema of the values of f that have the given kind.
+func kindSchema(f *Field, kind Kind) *tree.OrderedMap {
+	switch kind {
+	case KindObject:
+		return objectSchema(f)
+	case KindArray:
+		schema := tree.NewOrderedMap()
+		schema.Set("type", "array")
+		if f.Items != nil {
+			schema.Set("items", fieldSchema(f.Items))
+		}
+		return schema
+	case KindDatetime:
+		return stringSchema("date-time")
+	case KindDate:
+		return stringSchema("date")
+	case KindTime:
+		return stringSchema("time")
+	case KindString, KindInteger, KindNumber, KindBoolean, KindNull:
+	}
+
+	schema := tree.NewOrderedMap()
+	schema.Set("type", string(kind))
+	return schema
+}
+
+// stringSchema returns the schema of strings of the given format.
+func stringSchema(format string) *tree.OrderedMap {
+	schema := tree.NewOrderedMap()
+	schema.Set("type", "string")
+	schema.Set("format", format)
+	return schema
+}
+
+// objectSchema returns the schema of the mappings observed for f.
+func objectSchema(f *Field) *tree.OrderedMap {
+	schema := tree.NewOrderedMap()
+	schema.Set("type", "object")
+
+	props := f.Properties()
+	if len(props) == 0 {
+		return schema
+	}
+
+	properties := tree.NewOrderedMap()
+	var required []any
+	for _, prop := range props {
+		properties.Set(prop.Name, fieldSchema(prop))
+		if f.Required(prop) {
+			required = append(required, prop.Name)
+		}
+	}
+	schema.Set("properties", properties)
+	if len(required) > 0 {
+		schema.Set("required", required)
+	}
+	return schema
+}
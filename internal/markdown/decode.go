@@ -0,0 +1,130 @@
+package markdown
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/sushichan044/mdfm/internal/tree"
+)
+
+// Decoder decodes a front matter block into v.
+// Errors in the content of the block are reported as *DecodeError.
+type Decoder func(block *Block, v any) error
+
+// Unmarshal decodes the block with the decoder of its format.
+func Unmarshal(block *Block, v any) error {
+	if err := block.Format.Unmarshal(block.Raw, v); err != nil {
+		return newDecodeError(block, err)
+	}
+	return nil
+}
+
+// UnifiedDecoder returns a Decoder that converts the block to a format-independent tree
+// (see Generic) and decodes the tree with tree.Decode, reading field names from the
+// struct tag named tag. Values are therefore converted the same way regardless of the format.
+func UnifiedDecoder(tag string) Decoder {
+	return func(block *Block, v any) error {
+		generic, err := Generic(block)
+		if err != nil {
+			return err
+		}
+		if dErr := tree.Decode(generic, v, tag); dErr != nil {
+			return newFieldDecodeError(block, dErr)
+		}
+		return nil
+	}
+}
+
+// Generic decodes the block into a format-independent tree as returned by tree.Normalize.
+// JSON numbers are decoded as int64 when they are integers, like in the other formats.
+func Generic(block *Block) (any, error) {
+	unmarshal := block.Format.Unmarshal
+	if block.Format.Name == FormatJSON {
+		unmarshal = unmarshalJSONNumbers
+	}
+
+	var v any
+	if err := unmarshal(block.Raw, &v); err != nil {
+		return nil, newDecodeError(block, err)
+	}
+	return tree.Normalize(v), nil
+}
+
+// DecodeOrdered decodes the block into om, keeping the keys of every mapping in source order.
+//
+// YAML and JSON blocks are decoded with the Unmarshaler implementations of tree.OrderedMap.
+// The TOML decoder does not preserve order, so the order of TOML keys is restored from the
+// order in which they are defined.
+func DecodeOrdered(block *Block, om *tree.OrderedMap) error {
+	if block.Format.Name != FormatTOML {
+		return Unmarshal(block, om)
+	}
+
+	var generic map[string]any
+	meta, err := toml.Decode(string(block.Raw), &generic)
+	if err != nil {
+		return newDecodeError(block, err)
+	}
+
+	// Keys lists every key once per definition, in document order.
+	// Array of tables share the path of the array, which is what Ordered passes to compare.
+	order := make(map[string]int)
+	for i, key := range meta.Keys() {
+		path := strings.Join(key, "\x00")
+		if _, seen := order[path]; !seen {
+			order[path] = i
+		}
+	}
+	position := func(path []string, key string) int {
+		if i, ok := order[strings.Join(append(slices.Clip(path), key), "\x00")]; ok {
+			return i
+		}
+		return len(order)
+	}
+
+	ordered, _ := tree.Ordered(tree.Normalize(generic), func(path []string, a, b string) int {
+		return cmp.Or(cmp.Compare(position(path, a), position(path, b)), strings.Compare(a, b))
+	}).(*tree.OrderedMap)
+	*om = *ordered
+	return nil
+}
+
+// unmarshalJSONNumbers is like json.Unmarshal but keeps numbers as json.Number.
+func unmarshalJSONNumbers(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		// Let encoding/json report the trailing data.
+		return json.Unmarshal(data, v)
+	}
+	return nil
+}
+
+// newFieldDecodeError reports a tree.Decode error at the position of the offending key.
+func newFieldDecodeError(block *Block, err error) *DecodeError {
+	decodeErr := &DecodeError{
+		Format: block.Format.Name,
+		Reason: err.Error(),
+		Err:    err,
+	}
+
+	var fieldErr *tree.FieldError
+	if !errors.As(err, &fieldErr) {
+		return decodeErr
+	}
+	if pos, ok := KeyPositions(block)[fieldErr.Path]; ok {
+		decodeErr.Line = pos.Line
+		decodeErr.Column = pos.Column
+		decodeErr.Snippet = lineContent(block.Raw, pos.Line-block.Line+1)
+	}
+	return decodeErr
+}
@@ -0,0 +1,114 @@
+package markdown
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/sushichan044/mdfm/internal/tree"
+)
+
+// Encode renders value as a front matter section in the named built-in format, including the
+// delimiter lines, so that it can be written in front of the body of a document.
+//
+// When block is non-nil and has the same format, the section is an edit of block: the lines
+// around the data are kept, the data is kept byte-for-byte if value is deeply equal to old
+// (the tree decoded from block, see tree.Merge), and only the lines of the keys that changed
+// are rendered again in YAML and TOML, keeping comments and the line terminator of block.
+// Otherwise a new section with the default delimiters of the format is rendered.
+func Encode(format string, block *Block, old any, value *tree.OrderedMap) ([]byte, error) {
+	if block == nil || block.Format.Name != format {
+		return encodeNew(format, value)
+	}
+
+	if reflect.DeepEqual(old, value) {
+		return slicesConcat(block.Head, block.Raw, block.Tail), nil
+	}
+
+	var data []byte
+	var err error
+	switch format {
+	case FormatYAML:
+		data, err = encodeYAMLEdit(block, old, value)
+	case FormatTOML:
+		data, err = encodeTOMLEdit(string(block.Raw), old, value)
+	case FormatJSON:
+		data, err = encodeJSON(value, detectIndent(block.Raw, "  "))
+	default:
+		return nil, fmt.Errorf("cannot encode %s frontmatter", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return slicesConcat(block.Head, withTrailingNewLines(data, block.Raw), block.Tail), nil
+}
+
+func encodeNew(format string, value *tree.OrderedMap) ([]byte, error) {
+	switch format {
+	case FormatYAML:
+		data, err := encodeYAML(nil, nil, value)
+		if err != nil {
+			return nil, err
+		}
+		return slicesConcat([]byte("---\n"), data, []byte("---\n")), nil
+	case FormatTOML:
+		data, err := encodeTOML(value)
+		if err != nil {
+			return nil, err
+		}
+		return slicesConcat([]byte("+++\n"), data, []byte("+++\n")), nil
+	case FormatJSON:
+		data, err := encodeJSON(value, "  ")
+		if err != nil {
+			return nil, err
+		}
+		// A JSON object is recognized as front matter when followed by an empty line.
+		return append(bytes.TrimRight(data, "\n"), "\n\n"...), nil
+	default:
+		return nil, fmt.Errorf("cannot encode %s frontmatter", format)
+	}
+}
+
+// withTrailingNewLines replaces the trailing line terminators of data with those of raw,
+// so that e.g. CRLF line endings are kept.
+func withTrailingNewLines(data, raw []byte) []byte {
+	trailing := raw[len(bytes.TrimRight(raw, "\r\n")):]
+	data = bytes.TrimRight(data, "\r\n")
+	if len(data) == 0 {
+		return nil
+	}
+	if len(trailing) == 0 {
+		trailing = []byte("\n")
+	}
+	return slicesConcat(data, trailing)
+}
+
+func slicesConcat(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+// detectIndent returns the indentation of the first indented line in raw, or def if there is none.
+func detectIndent(raw []byte, def string) string {
+	for line := range strings.SplitSeq(string(raw), "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" || trimmed == line || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		return line[:len(line)-len(trimmed)]
+	}
+	return def
+}
+
+func encodeJSON(value *tree.OrderedMap, indent string) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", indent)
+	if err := enc.Encode(value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
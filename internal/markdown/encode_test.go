@@ -0,0 +1,175 @@
+package markdown_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/mdfm/internal/markdown"
+	"github.com/sushichan044/mdfm/internal/tree"
+)
+
+// encodeEdit decodes the frontmatter of input, applies edit to a copy and re-encodes it.
+func encodeEdit(t *testing.T, input string, edit func(om *tree.OrderedMap)) string {
+	t.Helper()
+
+	block := parseBlock(t, input)
+	var old, value tree.OrderedMap
+	require.NoError(t, markdown.DecodeOrdered(block, &old))
+	require.NoError(t, markdown.DecodeOrdered(block, &value))
+	edit(&value)
+
+	data, err := markdown.Encode(block.Format.Name, block, &old, &value)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestEncode_YAML(t *testing.T) {
+	input := "---\n# Post metadata\ntitle: 'Hello'  # shown in lists\ntags:\n    - a\n    - b\ndraft: true\n---\nBody"
+
+	t.Run("unchanged", func(t *testing.T) {
+		got := encodeEdit(t, input, func(*tree.OrderedMap) {})
+		assert.Equal(t, "---\n# Post metadata\ntitle: 'Hello'  # shown in lists\ntags:\n    - a\n    - b\ndraft: true\n---\n", got)
+	})
+
+	t.Run("comments and styles are kept", func(t *testing.T) {
+		got := encodeEdit(t, input, func(om *tree.OrderedMap) {
+			om.Set("title", "Bye")
+			om.Delete("draft")
+			om.Set("tags", []any{"a", "b", "c"})
+			om.Set("date", "2024-01-02")
+		})
+		assert.Equal(t, "---\n# Post metadata\ntitle: 'Bye' # shown in lists\ntags:\n    - a\n    - b\n    - c\ndate: \"2024-01-02\"\n---\n", got)
+	})
+
+	t.Run("nested mappings are patched", func(t *testing.T) {
+		input := "---\nseo:\n  desc: >\n    folded\n    text\n  # keywords\n  kw:   [a]\ndraft: true\n---\n"
+		got := encodeEdit(t, input, func(om *tree.OrderedMap) {
+			item, _ := om.Get("seo")
+			seo, _ := item.(*tree.OrderedMap)
+			seo.Delete("desc")
+			seo.Set("image", "a.png")
+		})
+		assert.Equal(t, "---\nseo:\n  # keywords\n  kw:   [a]\n  image: a.png\ndraft: true\n---\n", got)
+	})
+
+	t.Run("flow mappings are rendered again", func(t *testing.T) {
+		got := encodeEdit(t, "---\r\n{title: Hello, draft: true}\r\n---\r\n", func(om *tree.OrderedMap) {
+			om.Set("draft", false)
+		})
+		assert.Equal(t, "---\r\n{title: Hello, draft: false}\r\n---\r\n", got)
+	})
+
+	t.Run("renamed keys keep their value", func(t *testing.T) {
+		got := encodeEdit(t, "---\ntitle: Hello\ncategories: [go, web] # topics\n---\n", func(om *tree.OrderedMap) {
+			om.Rename("categories", "tags")
+		})
+		assert.Equal(t, "---\ntitle: Hello\ntags: [go, web] # topics\n---\n", got)
+	})
+}
+
+func TestEncode_TOML(t *testing.T) {
+	input := "+++\ntitle = \"Hello\"\ndate = 2024-01-02T00:00:00Z\n\n[seo]\ndescription = \"d\"\n\n[[authors]]\nname = \"alice\"\n+++\nBody"
+
+	t.Run("new keys are added to their table", func(t *testing.T) {
+		got := encodeEdit(t, input, func(om *tree.OrderedMap) {
+			om.Set("title", "Bye")
+			om.Set("count", int64(3))
+			require.NoError(t, om.SetPath("seo.keywords", []any{"a"}))
+		})
+		assert.Equal(t, "+++\ntitle = \"Bye\"\ndate = 2024-01-02T00:00:00Z\ncount = 3\n\n[seo]\ndescription = \"d\"\nkeywords = [\"a\"]\n\n[[authors]]\nname = \"alice\"\n+++\n", got)
+	})
+
+	t.Run("comments, formatting and local dates are kept", func(t *testing.T) {
+		input := "+++\n# Post metadata\ntitle =  'Hello'  # shown in lists\ndate = 2023-05-01\nat = 10:30:00\nlocal = 2023-05-01 10:00:00\ntags = [\n  \"a\", # first\n  \"b\",\n]\ndraft = true\n+++\n"
+		got := encodeEdit(t, input, func(om *tree.OrderedMap) {
+			om.Set("title", "Bye")
+			om.Delete("draft")
+		})
+		assert.Equal(t, "+++\n# Post metadata\ntitle =  \"Bye\"  # shown in lists\ndate = 2023-05-01\nat = 10:30:00\nlocal = 2023-05-01 10:00:00\ntags = [\n  \"a\", # first\n  \"b\",\n]\n+++\n", got)
+	})
+
+	t.Run("renamed keys keep their value", func(t *testing.T) {
+		got := encodeEdit(t, "+++\ntitle = \"Hello\"\ncategories = ['go', 'web'] # topics\n+++\n", func(om *tree.OrderedMap) {
+			om.Rename("categories", "tags")
+		})
+		assert.Equal(t, "+++\ntitle = \"Hello\"\ntags = ['go', 'web'] # topics\n+++\n", got)
+	})
+
+	t.Run("dotted keys", func(t *testing.T) {
+		got := encodeEdit(t, "+++\nsite.name = \"mdfm\" # name\nsite.url = \"a\"\n+++\n", func(om *tree.OrderedMap) {
+			require.NoError(t, om.SetPath("site.url", "b"))
+			require.NoError(t, om.SetPath("site.lang", "en"))
+		})
+		assert.Equal(t, "+++\nsite.name = \"mdfm\" # name\nsite.url = \"b\"\nsite.lang = \"en\"\n+++\n", got)
+	})
+
+	t.Run("tables", func(t *testing.T) {
+		got := encodeEdit(t, input, func(om *tree.OrderedMap) {
+			om.Delete("seo")
+			authors, _ := om.Get("authors")
+			list, _ := authors.([]any)
+			bob := tree.NewOrderedMap()
+			bob.Set("name", "bob")
+			om.Set("authors", append(list, bob))
+			extra := tree.NewOrderedMap()
+			extra.Set("a", int64(1))
+			om.Set("extra", extra)
+		})
+		assert.Equal(t, "+++\ntitle = \"Hello\"\ndate = 2024-01-02T00:00:00Z\n\n[[authors]]\nname = \"alice\"\n\n[[authors]]\nname = \"bob\"\n\n[extra]\na = 1\n+++\n", got)
+	})
+
+	t.Run("reordered keys are rendered again", func(t *testing.T) {
+		got := encodeEdit(t, "+++\n# comment\na = 1\nb = 2\n+++\n", func(om *tree.OrderedMap) {
+			om.Delete("a")
+			om.Set("a", int64(1))
+		})
+		assert.Equal(t, "+++\nb = 2\na = 1\n+++\n", got)
+	})
+}
+
+func TestEncode_JSON(t *testing.T) {
+	t.Run("bare object keeps its indentation", func(t *testing.T) {
+		input := "{\n    \"title\": \"Hello\",\n    \"tags\": [\"a\"]\n}\n\nBody"
+		got := encodeEdit(t, input, func(om *tree.OrderedMap) {
+			om.Set("title", "<Bye>")
+		})
+		assert.Equal(t, "{\n    \"title\": \"<Bye>\",\n    \"tags\": [\n        \"a\"\n    ]\n}\n\n", got)
+	})
+
+	t.Run("delimited", func(t *testing.T) {
+		input := ";;;\n{\"title\": \"Hello\"}\n;;;\nBody"
+		got := encodeEdit(t, input, func(om *tree.OrderedMap) {
+			om.Set("draft", false)
+		})
+		assert.Equal(t, ";;;\n{\n  \"title\": \"Hello\",\n  \"draft\": false\n}\n;;;\n", got)
+	})
+}
+
+func TestEncode_New(t *testing.T) {
+	value := tree.NewOrderedMap()
+	value.Set("title", "Hello")
+	value.Set("tags", []any{"a"})
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{format: markdown.FormatYAML, expected: "---\ntitle: Hello\ntags:\n  - a\n---\n"},
+		{format: markdown.FormatTOML, expected: "+++\ntitle = \"Hello\"\ntags = [\"a\"]\n+++\n"},
+		{format: markdown.FormatJSON, expected: "{\n  \"title\": \"Hello\",\n  \"tags\": [\n    \"a\"\n  ]\n}\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			data, err := markdown.Encode(tt.format, nil, nil, value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(data))
+		})
+	}
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := markdown.Encode("hcl", nil, nil, value)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,740 @@
+package markdown
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/sushichan044/mdfm/internal/tree"
+)
+
+//nolint:gochecknoglobals // Read-only pattern.
+var bareTOMLKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// encodeTOML renders value as TOML, keeping the order of its keys.
+//
+// Within each table, plain keys come first, followed by sub-tables and arrays of tables,
+// as required by TOML. Keys with a nil value are left out since TOML has no null.
+func encodeTOML(value *tree.OrderedMap) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeTOMLTable(&buf, nil, value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimLeft(buf.Bytes(), "\n"), nil
+}
+
+func writeTOMLTable(buf *bytes.Buffer, path []string, table *tree.OrderedMap) error {
+	var nested []string
+	for key, value := range table.All() {
+		if isTOMLTable(value) || isTOMLArrayOfTables(value) {
+			nested = append(nested, key)
+			continue
+		}
+		if value == nil {
+			continue
+		}
+
+		s, err := tomlValue(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", strings.Join(append(path, key), "."), err)
+		}
+		fmt.Fprintf(buf, "%s = %s\n", tomlKey(key), s)
+	}
+
+	for _, key := range nested {
+		value, _ := table.Get(key)
+		if err := writeTOMLNested(buf, append(path[:len(path):len(path)], key), value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTOMLNested writes the table or array of tables value at path, headers included.
+func writeTOMLNested(buf *bytes.Buffer, path []string, value any) error {
+	header := tomlKeyPath(path)
+	if sub, ok := value.(*tree.OrderedMap); ok {
+		fmt.Fprintf(buf, "\n[%s]\n", header)
+		return writeTOMLTable(buf, path, sub)
+	}
+	for _, item := range value.([]any) { //nolint:forcetypeassert // Checked by isTOMLArrayOfTables.
+		fmt.Fprintf(buf, "\n[[%s]]\n", header)
+		if err := writeTOMLTable(buf, path, item.(*tree.OrderedMap)); err != nil { //nolint:forcetypeassert // Same.
+			return err
+		}
+	}
+	return nil
+}
+
+func isTOMLTable(v any) bool {
+	_, ok := v.(*tree.OrderedMap)
+	return ok
+}
+
+func isTOMLArrayOfTables(v any) bool {
+	list, ok := v.([]any)
+	if !ok || len(list) == 0 {
+		return false
+	}
+	for _, item := range list {
+		if !isTOMLTable(item) {
+			return false
+		}
+	}
+	return true
+}
+
+func tomlKey(key string) string {
+	if bareTOMLKey.MatchString(key) {
+		return key
+	}
+	return tomlString(key)
+}
+
+func tomlKeyPath(path []string) string {
+	keys := make([]string, len(path))
+	for i, key := range path {
+		keys[i] = tomlKey(key)
+	}
+	return strings.Join(keys, ".")
+}
+
+// tomlValue renders an inline TOML value.
+func tomlValue(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return tomlString(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return tomlFloat(v), nil
+	case time.Time:
+		return tree.FormatTime(v), nil
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			s, err := tomlValue(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, s)
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case *tree.OrderedMap:
+		items := make([]string, 0, v.Len())
+		for key, item := range v.All() {
+			if item == nil {
+				continue
+			}
+			s, err := tomlValue(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, tomlKey(key)+" = "+s)
+		}
+		return "{" + strings.Join(items, ", ") + "}", nil
+	case nil:
+		return "", errors.New("TOML cannot represent null values in arrays")
+	default:
+		return "", fmt.Errorf("TOML cannot represent %T", v)
+	}
+}
+
+func tomlString(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&sb, `\u%04X`, r)
+				continue
+			}
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+func tomlFloat(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "nan"
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	}
+
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eE") {
+		s += ".0"
+	}
+	return s
+}
+
+// errTOMLLayout is returned by patchTOML when an edit does not fit the layout of the document.
+var errTOMLLayout = errors.New("edit does not fit the layout of the TOML document")
+
+// encodeTOMLEdit renders value as an edit of the TOML document raw, which was decoded as old.
+// The document is patched with patchTOML, or rendered again with encodeTOML if it cannot be.
+func encodeTOMLEdit(raw string, old any, value *tree.OrderedMap) ([]byte, error) {
+	if o, ok := old.(*tree.OrderedMap); ok && strings.TrimSpace(raw) != "" {
+		data, err := patchTOML(raw, o, value)
+		if !errors.Is(err, errTOMLLayout) {
+			return data, err
+		}
+	}
+	return encodeTOML(value)
+}
+
+// patchTOML renders value as an edit of raw, which was decoded as old. Pairs whose value did not
+// change are kept byte-for-byte, and so are comments and empty lines. A changed value is rendered
+// again after the key it is assigned to, removed keys and tables are deleted, new keys are added
+// after the last pair of their table and new tables at the end of the document.
+//
+// It returns errTOMLLayout if the edit cannot be made that way, e.g. when keys are reordered or a
+// table is replaced with another value, and when the patched document would not decode as value.
+func patchTOML(raw string, old, value *tree.OrderedMap) ([]byte, error) {
+	if !sameKeyOrder(old, value) {
+		return nil, errTOMLLayout
+	}
+	if !strings.HasSuffix(raw, "\n") {
+		raw += "\n"
+	}
+	doc, ok := parseTOMLDocument(raw)
+	if !ok {
+		return nil, errTOMLLayout
+	}
+
+	p := &tomlPatch{
+		doc:      doc,
+		old:      old,
+		value:    value,
+		lines:    slices.Clone(doc.lines),
+		inserted: make(map[int][]string),
+		renamed:  make(map[string]bool),
+	}
+	for i := range doc.statements {
+		if err := p.patchStatement(i); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.add(nil, value); err != nil {
+		return nil, err
+	}
+
+	data := p.bytes()
+	var check tree.OrderedMap
+	if _, err := toml.Decode(string(data), &check); err != nil || !tree.Equal(&check, withoutNulls(value)) {
+		return nil, errTOMLLayout
+	}
+	return data, nil
+}
+
+// sameKeyOrder reports whether the keys that old and value have in common are in the same order
+// in every mapping, as the keys of a patched document keep their place.
+func sameKeyOrder(old, value any) bool {
+	switch o := old.(type) {
+	case *tree.OrderedMap:
+		v, ok := value.(*tree.OrderedMap)
+		if !ok {
+			return true
+		}
+		var common []string
+		for key := range o.All() {
+			if _, found := v.Get(key); found {
+				common = append(common, key)
+			}
+		}
+		i := 0
+		for key, item := range v.All() {
+			oldItem, found := o.Get(key)
+			if !found {
+				continue
+			}
+			if common[i] != key || !sameKeyOrder(oldItem, item) {
+				return false
+			}
+			i++
+		}
+		return true
+	case []any:
+		v, ok := value.([]any)
+		if !ok {
+			return true
+		}
+		for i := range min(len(o), len(v)) {
+			if !sameKeyOrder(o[i], v[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return true
+	}
+}
+
+// withoutNulls returns v without the null values of its mappings, which TOML leaves out.
+func withoutNulls(v any) any {
+	switch v := v.(type) {
+	case *tree.OrderedMap:
+		om := tree.NewOrderedMap()
+		for key, item := range v.All() {
+			if item != nil {
+				om.Set(key, withoutNulls(item))
+			}
+		}
+		return om
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = withoutNulls(item)
+		}
+		return items
+	default:
+		return v
+	}
+}
+
+type (
+	// tomlDocument is a TOML document split into lines and statements, see parseTOMLDocument.
+	tomlDocument struct {
+		lines      []string
+		statements []tomlStatement
+
+		// kinds tells how each path is defined, by tomlPathKey.
+		kinds map[string]tomlKind
+
+		// newline is the line terminator used by the document.
+		newline string
+	}
+
+	// tomlStatement is a key/value pair or a table header of a TOML document, on lines[start:end].
+	tomlStatement struct {
+		start, end int
+
+		// path is the path of the key or table. The tables of an array of tables are
+		// identified by their index, as in tomlLookup.
+		path []string
+
+		// section is the path of the table a pair is defined in, that of the last header.
+		section []string
+
+		// header is set for [table] and [[array]] headers.
+		header bool
+
+		// eq is the index of the "=" of a pair in its first line.
+		eq int
+
+		// comment is the comment that ends the statement, with the white space before it.
+		comment string
+	}
+
+	// tomlKind tells how a path is defined in a TOML document.
+	tomlKind int
+
+	// tomlPatch collects the edits made to a TOML document by patchTOML.
+	tomlPatch struct {
+		doc        *tomlDocument
+		old, value *tree.OrderedMap
+
+		// lines are the lines of the patched document; the lines of removed statements are empty.
+		lines []string
+
+		// inserted are the lines to insert before each line.
+		inserted map[int][]string
+
+		// appended are the tables to write after the last line.
+		appended bytes.Buffer
+
+		// renamed are the new keys of the pairs that were renamed, by tomlPathKey.
+		renamed map[string]bool
+	}
+)
+
+const (
+	tomlUndefined tomlKind = iota
+	tomlPair
+	tomlTable
+	tomlArray
+)
+
+// parseTOMLDocument splits a TOML document into statements. Lines that are empty or only hold a
+// comment are not part of any statement. It returns false if a statement cannot be delimited.
+func parseTOMLDocument(raw string) (*tomlDocument, bool) {
+	doc := &tomlDocument{lines: strings.SplitAfter(raw, "\n"), kinds: make(map[string]tomlKind), newline: "\n"}
+	if strings.Contains(raw, "\r\n") {
+		doc.newline = "\r\n"
+	}
+
+	arrays := make(map[string]int)
+	var section []string
+	for start := 0; start < len(doc.lines); {
+		trimmed := strings.TrimSpace(doc.lines[start])
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			start++
+			continue
+		}
+
+		st, code, ok := doc.scanStatement(start)
+		if !ok {
+			return nil, false
+		}
+		if st.header {
+			st.path = doc.defineTable(strings.TrimSpace(code), arrays)
+			section = st.path
+		} else {
+			st.section = section
+			st.path = slices.Concat(section, splitTOMLKey(code[:st.eq]))
+			doc.define(st.path, len(section), tomlPair)
+		}
+		doc.statements = append(doc.statements, st)
+		start = st.end
+	}
+	return doc, true
+}
+
+// scanStatement delimits the statement that starts on line start, as the shortest run of lines that
+// is valid TOML on its own. It returns the statement along with its text, without the final comment.
+func (d *tomlDocument) scanStatement(start int) (tomlStatement, string, bool) {
+	for end := start + 1; end <= len(d.lines); end++ {
+		text := strings.Join(d.lines[start:end], "")
+		if !validTOML(text) {
+			continue
+		}
+
+		// The comment starts at the first "#" of the last line that leaves valid TOML before it.
+		code := strings.TrimRight(text, "\r\n")
+		for i := strings.LastIndexByte(code, '\n') + 1; i < len(code); i++ {
+			if code[i] == '#' && validTOML(code[:i]) {
+				code = code[:i]
+				break
+			}
+		}
+		code = strings.TrimRightFunc(code, unicode.IsSpace)
+
+		st := tomlStatement{
+			start:   start,
+			end:     end,
+			header:  strings.HasPrefix(strings.TrimSpace(code), "["),
+			comment: strings.TrimRight(text[len(code):], "\r\n"),
+		}
+		if !st.header {
+			if st.eq = tomlIndexOutsideQuotes(code, '='); st.eq < 0 {
+				return tomlStatement{}, "", false
+			}
+		}
+		return st, code, true
+	}
+	return tomlStatement{}, "", false
+}
+
+// defineTable records the table defined by a header and returns its path. Parts that refer to an
+// array of tables refer to its last table, which arrays counts.
+func (d *tomlDocument) defineTable(header string, arrays map[string]int) []string {
+	isArray := strings.HasPrefix(header, "[[")
+	if isArray {
+		header = header[2 : len(header)-2]
+	} else {
+		header = header[1 : len(header)-1]
+	}
+
+	var path []string
+	for i, part := range splitTOMLKey(header) {
+		if n, ok := arrays[tomlPathKey(path)]; ok && i > 0 {
+			path = append(path, strconv.Itoa(n-1))
+		}
+		path = append(path, part)
+	}
+	if isArray {
+		key := tomlPathKey(path)
+		arrays[key]++
+		d.define(path, 0, tomlArray)
+		path = append(path, strconv.Itoa(arrays[key]-1))
+	}
+	d.define(path, 0, tomlTable)
+	return path
+}
+
+// define records that path is defined as kind, and that the tables above it are defined, except
+// for the first skip parts of path.
+func (d *tomlDocument) define(path []string, skip int, kind tomlKind) {
+	for i := skip + 1; i < len(path); i++ {
+		if key := tomlPathKey(path[:i]); d.kinds[key] == tomlUndefined {
+			d.kinds[key] = tomlTable
+		}
+	}
+	d.kinds[tomlPathKey(path)] = kind
+}
+
+// headerPath returns path without the indexes of the tables of arrays of tables, as written in headers.
+func (d *tomlDocument) headerPath(path []string) []string {
+	var header []string
+	for i, part := range path {
+		if i == 0 || d.kinds[tomlPathKey(path[:i])] != tomlArray {
+			header = append(header, part)
+		}
+	}
+	return header
+}
+
+// sectionEnd returns the line after the last statement of the table whose header is statements[i].
+func (d *tomlDocument) sectionEnd(i int) int {
+	end := d.statements[i].end
+	for _, st := range d.statements[i+1:] {
+		if st.header {
+			break
+		}
+		end = st.end
+	}
+	return end
+}
+
+// insertionPoint returns the line before which a pair can be added to the table at path, after its
+// last pair or its header, along with the section the pair is in and its indentation.
+// Pairs of the root table are added at the top if it has none.
+func (d *tomlDocument) insertionPoint(path []string) (int, []string, string, bool) {
+	for _, st := range slices.Backward(d.statements) {
+		switch {
+		case st.header && slices.Equal(st.path, path):
+			return st.end, st.path, "", true
+		case !st.header && len(st.section) <= len(path) && len(st.path) > len(path) &&
+			slices.Equal(st.path[:len(path)], path):
+			line := d.lines[st.start]
+			return st.end, st.section, line[:len(line)-len(strings.TrimLeft(line, " \t"))], true
+		}
+	}
+	return 0, nil, "", len(path) == 0
+}
+
+// patchStatement removes the i-th statement if its key was removed, or renders its value again if it changed.
+func (p *tomlPatch) patchStatement(i int) error {
+	st := p.doc.statements[i]
+	item, found := tomlLookup(p.value, st.path)
+	if st.header {
+		switch {
+		case !found:
+			// The empty lines that separate the table from the previous statement go with it.
+			start := st.start
+			for start > 0 && strings.TrimSpace(p.doc.lines[start-1]) == "" {
+				start--
+			}
+			p.remove(start, p.doc.sectionEnd(i))
+		case !isTOMLTable(item):
+			return errTOMLLayout
+		}
+		return nil
+	}
+
+	oldItem, _ := tomlLookup(p.old, st.path)
+	first := p.doc.lines[st.start]
+	switch {
+	case item != nil && tree.Equal(oldItem, item):
+		return nil
+	case item != nil:
+		s, err := tomlValue(item)
+		if err != nil {
+			return fmt.Errorf("%s: %w", strings.Join(st.path, "."), err)
+		}
+		after := first[st.eq+1:]
+		p.replace(st, first[:st.eq+1]+after[:len(after)-len(strings.TrimLeft(after, " \t"))]+s+st.comment)
+	case !found:
+		if key, ok := p.renamedKey(st.path, oldItem); ok {
+			indent := first[:len(first)-len(strings.TrimLeft(first, " \t"))]
+			rel := slices.Concat(st.path[len(st.section):len(st.path)-1], []string{key})
+			text := strings.TrimRight(strings.Join(p.doc.lines[st.start:st.end], ""), "\r\n")
+			p.replace(st, indent+tomlKeyPath(rel)+" "+text[st.eq:])
+			return nil
+		}
+		p.remove(st.start, st.end)
+	default:
+		p.remove(st.start, st.end)
+	}
+	return nil
+}
+
+// renamedKey finds a new key next to the removed key at path that holds the same value, so that a
+// renamed key keeps its place, formatting and comment.
+func (p *tomlPatch) renamedKey(path []string, oldItem any) (string, bool) {
+	parent := path[:len(path)-1]
+	oldTable, _ := tomlLookup(p.old, parent)
+	table, _ := tomlLookup(p.value, parent)
+	o, ok := oldTable.(*tree.OrderedMap)
+	v, vOK := table.(*tree.OrderedMap)
+	if !ok || !vOK {
+		return "", false
+	}
+
+	for key, item := range v.All() {
+		keyPath := tomlPathKey(slices.Concat(parent, []string{key}))
+		if _, known := o.Get(key); known || p.renamed[keyPath] || !tree.Equal(oldItem, item) {
+			continue
+		}
+		p.renamed[keyPath] = true
+		return key, true
+	}
+	return "", false
+}
+
+// add adds the keys of the table at path that the document does not define.
+func (p *tomlPatch) add(path []string, table *tree.OrderedMap) error {
+	for key, item := range table.All() {
+		keyPath := append(path[:len(path):len(path)], key)
+
+		var err error
+		switch p.doc.kinds[tomlPathKey(keyPath)] {
+		case tomlPair:
+			// Patched by patchStatement.
+		case tomlTable:
+			sub, ok := item.(*tree.OrderedMap)
+			if !ok {
+				return errTOMLLayout
+			}
+			err = p.add(keyPath, sub)
+		case tomlArray:
+			err = p.addTables(keyPath, item)
+		case tomlUndefined:
+			err = p.addKey(path, key, item)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addTables adds the tables of the array of tables at path that the document does not define.
+func (p *tomlPatch) addTables(path []string, item any) error {
+	if !isTOMLArrayOfTables(item) {
+		return errTOMLLayout
+	}
+
+	header := p.doc.headerPath(path)
+	for i, table := range item.([]any) { //nolint:forcetypeassert // Checked by isTOMLArrayOfTables.
+		tablePath := append(path[:len(path):len(path)], strconv.Itoa(i))
+		sub := table.(*tree.OrderedMap) //nolint:forcetypeassert // Same.
+		if p.doc.kinds[tomlPathKey(tablePath)] != tomlUndefined {
+			if err := p.add(tablePath, sub); err != nil {
+				return err
+			}
+			continue
+		}
+
+		fmt.Fprintf(&p.appended, "\n[[%s]]\n", tomlKeyPath(header))
+		if err := writeTOMLTable(&p.appended, header, sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addKey adds key to the table at path: tables are written at the end of the document and other
+// values after the last pair of the table.
+func (p *tomlPatch) addKey(path []string, key string, item any) error {
+	keyPath := append(path[:len(path):len(path)], key)
+	switch {
+	case item == nil || p.renamed[tomlPathKey(keyPath)]:
+		return nil
+	case isTOMLTable(item) || isTOMLArrayOfTables(item):
+		return writeTOMLNested(&p.appended, p.doc.headerPath(keyPath), item)
+	}
+
+	s, err := tomlValue(item)
+	if err != nil {
+		return fmt.Errorf("%s: %w", strings.Join(keyPath, "."), err)
+	}
+	line, section, indent, ok := p.doc.insertionPoint(path)
+	if !ok {
+		return errTOMLLayout
+	}
+	p.inserted[line] = append(p.inserted[line], indent+tomlKeyPath(keyPath[len(section):])+" = "+s+p.doc.newline)
+	return nil
+}
+
+// replace replaces the lines of st with text, followed by the line terminator of st.
+func (p *tomlPatch) replace(st tomlStatement, text string) {
+	last := p.doc.lines[st.end-1]
+	p.remove(st.start, st.end)
+	p.lines[st.start] = text + last[len(strings.TrimRight(last, "\r\n")):]
+}
+
+func (p *tomlPatch) remove(start, end int) {
+	for i := start; i < end; i++ {
+		p.lines[i] = ""
+	}
+}
+
+// bytes returns the patched document.
+func (p *tomlPatch) bytes() []byte {
+	var buf bytes.Buffer
+	for i, line := range p.lines {
+		for _, inserted := range p.inserted[i] {
+			buf.WriteString(inserted)
+		}
+		buf.WriteString(line)
+	}
+	if p.appended.Len() == 0 {
+		return buf.Bytes()
+	}
+
+	data := bytes.TrimRight(buf.Bytes(), "\r\n")
+	if len(bytes.TrimSpace(data)) == 0 {
+		return bytes.TrimLeft(p.appended.Bytes(), "\n")
+	}
+	return slicesConcat(data, []byte(p.doc.newline), p.appended.Bytes())
+}
+
+// tomlLookup returns the value at path in v. Parts that follow an array are indexes.
+func tomlLookup(v any, path []string) (any, bool) {
+	for _, part := range path {
+		switch node := v.(type) {
+		case *tree.OrderedMap:
+			var found bool
+			if v, found = node.Get(part); !found {
+				return nil, false
+			}
+		case []any:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			v = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+func tomlPathKey(path []string) string {
+	return strings.Join(path, "\x00")
+}
+
+func validTOML(s string) bool {
+	var v map[string]any
+	_, err := toml.Decode(s, &v)
+	return err == nil
+}
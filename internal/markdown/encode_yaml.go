@@ -0,0 +1,494 @@
+package markdown
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/sushichan044/mdfm/internal/tree"
+)
+
+// errYAMLLayout is returned by patchYAMLLines when an edit does not fit the layout of the document.
+var errYAMLLayout = errors.New("edit does not fit the layout of the YAML document")
+
+// encodeYAMLEdit renders value as an edit of the YAML document of block, which was decoded as old.
+// The document is patched with patchYAMLLines, or rendered again from its nodes with encodeYAML if
+// it cannot be. Either way, the line terminator of the document is kept.
+func encodeYAMLEdit(block *Block, old any, value *tree.OrderedMap) ([]byte, error) {
+	if o, ok := old.(*tree.OrderedMap); ok && value.Len() > 0 {
+		data, err := patchYAMLLines(block, o, value)
+		if !errors.Is(err, errYAMLLayout) {
+			return data, err
+		}
+	}
+
+	data, err := encodeYAML(block.Raw, old, value)
+	if err != nil || !bytes.Contains(block.Raw, []byte("\r\n")) {
+		return data, err
+	}
+	return bytes.ReplaceAll(data, []byte("\n"), []byte("\r\n")), nil
+}
+
+func encodeYAML(raw []byte, old any, value *tree.OrderedMap) ([]byte, error) {
+	if value.Len() == 0 {
+		return nil, nil
+	}
+
+	var doc yaml.Node
+	if len(raw) > 0 {
+		if err := yaml.Unmarshal(raw, &doc); err != nil {
+			return nil, err
+		}
+	}
+
+	var root *yaml.Node
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		root = doc.Content[0]
+	} else {
+		doc = yaml.Node{Kind: yaml.DocumentNode}
+	}
+
+	patched, err := patchYAML(root, old, value)
+	if err != nil {
+		return nil, err
+	}
+	doc.Content = []*yaml.Node{patched}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(max(len(detectIndent(raw, "  ")), 2))
+	if eErr := enc.Encode(&doc); eErr != nil {
+		return nil, eErr
+	}
+	if cErr := enc.Close(); cErr != nil {
+		return nil, cErr
+	}
+	return buf.Bytes(), nil
+}
+
+// patchYAML updates node, which was decoded as old, to represent value.
+// Nodes whose value did not change are kept as is, including their comments and style.
+func patchYAML(node *yaml.Node, old, value any) (*yaml.Node, error) {
+	if node != nil && reflect.DeepEqual(old, value) {
+		return node, nil
+	}
+
+	switch v := value.(type) {
+	case *tree.OrderedMap:
+		o, ok := old.(*tree.OrderedMap)
+		if node == nil || node.Kind != yaml.MappingNode || !ok {
+			return replaceYAML(node, value)
+		}
+		return patchYAMLMapping(node, o, v)
+	case []any:
+		o, ok := old.([]any)
+		if node == nil || node.Kind != yaml.SequenceNode || !ok {
+			return replaceYAML(node, value)
+		}
+
+		content := make([]*yaml.Node, len(v))
+		for i, item := range v {
+			var err error
+			if i < len(o) && i < len(node.Content) {
+				content[i], err = patchYAML(node.Content[i], o[i], item)
+			} else {
+				content[i], err = newYAMLNode(item)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		node.Content = content
+		return node, nil
+	default:
+		return replaceYAML(node, value)
+	}
+}
+
+func patchYAMLMapping(node *yaml.Node, old, value *tree.OrderedMap) (*yaml.Node, error) {
+	pairs := make(map[string][2]*yaml.Node, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		pairs[node.Content[i].Value] = [2]*yaml.Node{node.Content[i], node.Content[i+1]}
+	}
+
+	content := make([]*yaml.Node, 0, value.Len()*2)
+	for key, item := range value.All() {
+		pair, found := pairs[key]
+		oldItem, known := old.Get(key)
+		if found && known {
+			valueNode, err := patchYAML(pair[1], oldItem, item)
+			if err != nil {
+				return nil, err
+			}
+			content = append(content, pair[0], valueNode)
+			continue
+		}
+
+		if renamed, ok := renamedYAMLPair(pairs, old, value, item); ok {
+			keyNode := *renamed[0]
+			keyNode.Value = key
+			content = append(content, &keyNode, renamed[1])
+			continue
+		}
+
+		keyNode, err := newYAMLNode(key)
+		if err != nil {
+			return nil, err
+		}
+		valueNode, err := newYAMLNode(item)
+		if err != nil {
+			return nil, err
+		}
+		content = append(content, keyNode, valueNode)
+	}
+	node.Content = content
+	return node, nil
+}
+
+// renamedYAMLPair finds the pair of a removed key holding item, so that a renamed key keeps
+// the style and comments of its value. The pair is removed from pairs.
+func renamedYAMLPair(pairs map[string][2]*yaml.Node, old, value *tree.OrderedMap, item any) ([2]*yaml.Node, bool) {
+	for oldKey, oldItem := range old.All() {
+		pair, found := pairs[oldKey]
+		if _, kept := value.Get(oldKey); !found || kept || !reflect.DeepEqual(oldItem, item) {
+			continue
+		}
+		delete(pairs, oldKey)
+		return pair, true
+	}
+	return [2]*yaml.Node{}, false
+}
+
+// replaceYAML returns a new node for value that keeps the comments of node.
+func replaceYAML(node *yaml.Node, value any) (*yaml.Node, error) {
+	replacement, err := newYAMLNode(value)
+	if err != nil || node == nil {
+		return replacement, err
+	}
+
+	replacement.HeadComment = node.HeadComment
+	replacement.LineComment = node.LineComment
+	replacement.FootComment = node.FootComment
+	if node.Kind == yaml.ScalarNode && replacement.Kind == yaml.ScalarNode &&
+		replacement.ShortTag() == node.ShortTag() && replacement.Style == 0 {
+		// Keep quotes or block styles of strings, unless the new value requires a specific style.
+		replacement.Style = node.Style
+	}
+	return replacement, nil
+}
+
+func newYAMLNode(value any) (*yaml.Node, error) {
+	switch v := value.(type) {
+	case *tree.OrderedMap:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for key, item := range v.All() {
+			keyNode, err := newYAMLNode(key)
+			if err != nil {
+				return nil, err
+			}
+			valueNode, err := newYAMLNode(item)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, keyNode, valueNode)
+		}
+		return node, nil
+	case []any:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range v {
+			itemNode, err := newYAMLNode(item)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, itemNode)
+		}
+		return node, nil
+	default:
+		var node yaml.Node
+		if err := node.Encode(value); err != nil {
+			return nil, err
+		}
+		return &node, nil
+	}
+}
+
+type (
+	// yamlPatch collects the edits made to a YAML document by patchYAMLLines.
+	yamlPatch struct {
+		// lines are the lines of the patched document; the lines of removed pairs are empty.
+		lines []string
+
+		// inserted are the lines to insert before each line.
+		inserted map[int][]string
+
+		// newline is the line terminator used by the document.
+		newline string
+
+		// indent is the indentation width of nested nodes rendered again.
+		indent int
+	}
+
+	// yamlPair is a key/value pair of a block mapping, on lines[start:end]. The comment and
+	// empty lines that follow the value are not part of the pair.
+	yamlPair struct {
+		key, value *yaml.Node
+		start, end int
+	}
+)
+
+// patchYAMLLines renders value as an edit of the YAML document of block, which was decoded as old.
+// Pairs whose value did not change are kept byte-for-byte, and so are comments and empty lines.
+// A pair whose value changed is rendered again in place, nested block mappings being patched the
+// same way, removed keys are deleted, and new keys are added after the last pair of their mapping.
+//
+// It returns errYAMLLayout if the edit cannot be made that way, e.g. when keys are reordered or the
+// document uses flow mappings at the top, and when the patched document would not decode as value.
+func patchYAMLLines(block *Block, old, value *tree.OrderedMap) ([]byte, error) {
+	raw := string(block.Raw)
+	newline := "\n"
+	if strings.Contains(raw, "\r\n") {
+		newline = "\r\n"
+	}
+	if !strings.HasSuffix(raw, "\n") {
+		raw += newline
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(raw), &doc); err != nil || len(doc.Content) == 0 || !sameKeyOrder(old, value) {
+		return nil, errYAMLLayout
+	}
+
+	p := &yamlPatch{
+		lines:    strings.SplitAfter(raw, "\n"),
+		inserted: make(map[int][]string),
+		newline:  newline,
+		indent:   max(len(detectIndent(block.Raw, "  ")), 2),
+	}
+	if err := p.patchMapping(doc.Content[0], old, value, len(p.lines)); err != nil {
+		return nil, err
+	}
+
+	data := p.bytes()
+	var check tree.OrderedMap
+	if err := DecodeOrdered(&Block{Format: block.Format, Raw: data}, &check); err != nil || !tree.Equal(&check, value) {
+		return nil, errYAMLLayout
+	}
+	return data, nil
+}
+
+// patchMapping patches the block mapping node, which ends before line end and was decoded as old.
+func (p *yamlPatch) patchMapping(node *yaml.Node, old, value *tree.OrderedMap, end int) error {
+	pairs, ok := p.pairs(node, end)
+	if !ok {
+		return errYAMLLayout
+	}
+
+	renamed := make(map[string]bool)
+	for _, pair := range pairs {
+		oldItem, _ := old.Get(pair.key.Value)
+		item, found := value.Get(pair.key.Value)
+
+		var err error
+		switch {
+		case found && tree.Equal(oldItem, item):
+			continue
+		case found:
+			err = p.patchPair(pair, oldItem, item)
+		default:
+			if key, ok := renamedYAMLKey(old, value, oldItem, renamed); ok {
+				err = p.renameKey(pair, key)
+			} else {
+				p.remove(pair.start, pair.end)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	last := pairs[len(pairs)-1]
+	indent := strings.Repeat(" ", pairs[0].key.Column-1)
+	for key, item := range value.All() {
+		if _, known := old.Get(key); known || renamed[key] {
+			continue
+		}
+		keyNode, err := newYAMLNode(key)
+		if err != nil {
+			return err
+		}
+		valueNode, err := newYAMLNode(item)
+		if err != nil {
+			return err
+		}
+		lines, err := p.render(keyNode, valueNode, indent)
+		if err != nil {
+			return err
+		}
+		p.inserted[last.end] = append(p.inserted[last.end], lines...)
+	}
+	return nil
+}
+
+// pairs delimits the pairs of the block mapping node, which ends before line end.
+// It returns false if node is not a block mapping with one key per line.
+func (p *yamlPatch) pairs(node *yaml.Node, end int) ([]yamlPair, bool) {
+	if node.Kind != yaml.MappingNode || node.Style&yaml.FlowStyle != 0 || len(node.Content) == 0 {
+		return nil, false
+	}
+
+	pairs := make([]yamlPair, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Kind != yaml.ScalarNode || key.Tag == "!!merge" || value.Kind == yaml.AliasNode || value.Anchor != "" {
+			return nil, false
+		}
+		pairs = append(pairs, yamlPair{key: key, value: value, start: key.Line - 1})
+	}
+
+	for i := range pairs {
+		next := end
+		if i+1 < len(pairs) {
+			next = pairs[i+1].start
+		}
+		if next <= pairs[i].start || strings.TrimSpace(p.lines[pairs[i].start][:pairs[i].key.Column-1]) != "" {
+			return nil, false
+		}
+		pairs[i].end = p.contentEnd(pairs[i], next)
+	}
+	return pairs, true
+}
+
+// contentEnd returns the line after the last line of the value of pair, which ends before line next.
+// Comment lines are part of the value when they are indented within a block value.
+func (p *yamlPatch) contentEnd(pair yamlPair, next int) int {
+	block := pair.value.Kind != yaml.ScalarNode || pair.value.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0
+	end := pair.start + 1
+	for i := pair.start + 1; i < next; i++ {
+		line := p.lines[i]
+		trimmed := strings.TrimSpace(line)
+		indented := len(line)-len(strings.TrimLeft(line, " ")) > pair.key.Column-1
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") && !(block && indented) {
+			continue
+		}
+		end = i + 1
+	}
+	return end
+}
+
+// patchPair renders the value of pair again, or patches it if both values are non-empty mappings
+// and it is a block mapping.
+func (p *yamlPatch) patchPair(pair yamlPair, oldItem, item any) error {
+	o, oOK := oldItem.(*tree.OrderedMap)
+	v, vOK := item.(*tree.OrderedMap)
+	if oOK && vOK && v.Len() > 0 && pair.value.Kind == yaml.MappingNode && pair.value.Style&yaml.FlowStyle == 0 &&
+		pair.value.Line > pair.key.Line {
+		return p.patchMapping(pair.value, o, v, pair.end)
+	}
+
+	valueNode, err := patchYAML(pair.value, oldItem, item)
+	if err != nil {
+		return err
+	}
+	line := p.lines[pair.start]
+	lines, err := p.render(pair.key, valueNode, line[:pair.key.Column-1])
+	if err != nil {
+		return err
+	}
+
+	// Comments that follow the value are kept in place, so they are not rendered twice.
+	for len(lines) > 1 && pair.end < len(p.lines) &&
+		strings.TrimSpace(lines[len(lines)-1]) == strings.TrimSpace(p.lines[pair.end]) &&
+		strings.HasPrefix(strings.TrimSpace(lines[len(lines)-1]), "#") {
+		lines = lines[:len(lines)-1]
+	}
+
+	p.remove(pair.start, pair.end)
+	p.inserted[pair.start] = append(p.inserted[pair.start], lines...)
+	return nil
+}
+
+// renameKey replaces the key of pair with key, keeping the rest of the pair as is.
+func (p *yamlPatch) renameKey(pair yamlPair, key string) error {
+	line := p.lines[pair.start]
+	prefix, rest := line[:pair.key.Column-1], line[pair.key.Column-1:]
+	if pair.key.Style != 0 || !strings.HasPrefix(rest, pair.key.Value) {
+		return errYAMLLayout
+	}
+
+	data, err := yaml.Marshal(key)
+	if err != nil {
+		return err
+	}
+	rendered := strings.TrimSuffix(string(data), "\n")
+	if strings.Contains(rendered, "\n") {
+		return errYAMLLayout
+	}
+	p.lines[pair.start] = prefix + rendered + rest[len(pair.key.Value):]
+	return nil
+}
+
+// renamedYAMLKey finds a new key of value that holds the same value as a removed key did, so that
+// a renamed key keeps its place, formatting and comment. The key is recorded in renamed.
+func renamedYAMLKey(old, value *tree.OrderedMap, oldItem any, renamed map[string]bool) (string, bool) {
+	for key, item := range value.All() {
+		if _, known := old.Get(key); known || renamed[key] || !tree.Equal(oldItem, item) {
+			continue
+		}
+		renamed[key] = true
+		return key, true
+	}
+	return "", false
+}
+
+// render renders the pair of key and value as lines starting with prefix, the first of which is
+// the key. Comments above and below the pair are left out as they are kept in place.
+func (p *yamlPatch) render(key, value *yaml.Node, prefix string) ([]string, error) {
+	keyNode, valueNode := *key, *value
+	keyNode.HeadComment, keyNode.FootComment = "", ""
+	valueNode.HeadComment, valueNode.FootComment = "", ""
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(p.indent)
+	if err := enc.Encode(&yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{&keyNode, &valueNode}}); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	indent := strings.Repeat(" ", len(prefix))
+	for i, line := range lines {
+		switch {
+		case i == 0:
+			lines[i] = prefix + line + p.newline
+		case line == "":
+			lines[i] = p.newline
+		default:
+			lines[i] = indent + line + p.newline
+		}
+	}
+	return lines, nil
+}
+
+func (p *yamlPatch) remove(start, end int) {
+	for i := start; i < end; i++ {
+		p.lines[i] = ""
+	}
+}
+
+// bytes returns the patched document.
+func (p *yamlPatch) bytes() []byte {
+	var buf bytes.Buffer
+	for i, line := range p.lines {
+		for _, inserted := range p.inserted[i] {
+			buf.WriteString(inserted)
+		}
+		buf.WriteString(line)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,141 @@
+package markdown
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+var (
+	linePattern   = regexp.MustCompile(`\bline (\d+)`)
+	columnPattern = regexp.MustCompile(`\bcolumn (\d+)`)
+	// positionPrefix matches the location prefixes of YAML and TOML decoder messages, e.g.
+	// "yaml: line 2: ", "yaml: unmarshal errors:" and "toml: line 2 (last key \"a\"): ".
+	positionPrefix = regexp.MustCompile(
+		`^(?:yaml: |toml: )?(?:unmarshal errors:)?(?:line \d+(?: column \d+)?(?: \(last key[^)]*\))?: )?`,
+	)
+)
+
+// DecodeError is returned by Parse when the frontmatter block cannot be decoded.
+type DecodeError struct {
+	// Format is the name of the frontmatter format.
+	Format string
+
+	// Line is the 1-based line number in the document where decoding failed, or 0 if unknown.
+	Line int
+
+	// Column is the 1-based column where decoding failed, or 0 if unknown.
+	Column int
+
+	// Snippet is the content of the line where decoding failed, if known.
+	Snippet string
+
+	// Reason describes the failure without the decoder specific location prefix.
+	Reason string
+
+	// Err is the error returned by the decoder.
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: invalid %s frontmatter: %s", e.Line, e.Format, e.Reason)
+	}
+	return fmt.Sprintf("invalid %s frontmatter: %s", e.Format, e.Reason)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// newDecodeError locates err within the block and translates the position into document coordinates.
+func newDecodeError(block *Block, err error) *DecodeError {
+	line, column := locate(block.Raw, err)
+
+	decodeErr := &DecodeError{
+		Format: block.Format.Name,
+		Reason: reason(err),
+		Err:    err,
+	}
+	if line > 0 {
+		decodeErr.Line = block.Line + line - 1
+		decodeErr.Column = column
+		decodeErr.Snippet = lineContent(block.Raw, line)
+	}
+	return decodeErr
+}
+
+// locate returns the 1-based line and column in raw that err refers to.
+// Zero values mean the position is unknown.
+func locate(raw []byte, err error) (int, int) {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+		tomlErr   toml.ParseError
+	)
+	switch {
+	case errors.As(err, &tomlErr):
+		return tomlErr.Position.Line, tomlErr.Position.Col
+	case errors.As(err, &syntaxErr):
+		return offsetPosition(raw, syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		return offsetPosition(raw, typeErr.Offset)
+	}
+
+	m := linePattern.FindStringSubmatch(err.Error())
+	if m == nil {
+		return 0, 0
+	}
+	line, _ := strconv.Atoi(m[1])
+	// Some decoders report the line after the last one when the input ends unexpectedly.
+	line = min(max(line, 1), lineCount(raw))
+
+	var column int
+	if c := columnPattern.FindStringSubmatch(err.Error()); c != nil {
+		column, _ = strconv.Atoi(c[1])
+	}
+	return line, column
+}
+
+// offsetPosition converts the offset reported by encoding/json into a line and column.
+// encoding/json reports the offset after the offending byte.
+func offsetPosition(raw []byte, offset int64) (int, int) {
+	off := int(min(max(offset-1, 0), int64(len(raw))))
+	lineStart := bytes.LastIndexByte(raw[:off], '\n') + 1
+	return lineAt(raw, off), off - lineStart + 1
+}
+
+// reason strips decoder specific location prefixes from err,
+// since the position is reported in document coordinates instead.
+func reason(err error) string {
+	var reasons []string
+	for _, line := range strings.Split(err.Error(), "\n") {
+		if r := strings.TrimSpace(positionPrefix.ReplaceAllString(strings.TrimSpace(line), "")); r != "" {
+			reasons = append(reasons, r)
+		}
+	}
+	if len(reasons) == 0 {
+		return err.Error()
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// lineContent returns the content of the 1-based line in data without the line terminator.
+func lineContent(data []byte, line int) string {
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	return strings.TrimRight(string(lines[line-1]), "\r\n")
+}
+
+// lineCount returns the number of lines in data, ignoring a trailing line terminator.
+func lineCount(data []byte) int {
+	return max(bytes.Count(bytes.TrimSuffix(data, []byte("\n")), []byte("\n"))+1, 1)
+}
@@ -0,0 +1,75 @@
+package markdown
+
+import (
+	"encoding/json"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// Names of the built-in frontmatter formats.
+const (
+	FormatYAML = "yaml"
+	FormatTOML = "toml"
+	FormatJSON = "json"
+)
+
+// Format describes a frontmatter format identified by its opening and closing delimiter lines.
+type Format struct {
+	// Name identifies the format, e.g. "yaml".
+	Name string
+
+	// Start is the line that opens the frontmatter block.
+	Start string
+
+	// End is the line that closes the frontmatter block.
+	End string
+
+	// Unmarshal decodes the content of the frontmatter block into v.
+	Unmarshal func(data []byte, v any) error
+
+	// FieldTag is the struct tag Unmarshal reads field names from, e.g. "yaml".
+	FieldTag string
+
+	// UnmarshalDelims makes the delimiter lines part of the data passed to Unmarshal.
+	UnmarshalDelims bool
+
+	// RequiresNewLine requires the closing delimiter to be followed by an empty line.
+	RequiresNewLine bool
+}
+
+// DefaultFormats returns the frontmatter formats that are recognized by default:
+//
+//   - YAML between `---` lines, or opened by `---yaml` and closed by `---`.
+//   - TOML between `+++` lines, or opened by `---toml` and closed by `---`.
+//   - JSON between `;;;` lines, or opened by `---json` and closed by `---`.
+//   - A single JSON object followed by an empty line.
+func DefaultFormats() []*Format {
+	return []*Format{
+		{Name: FormatYAML, Start: "---", End: "---", Unmarshal: yaml.Unmarshal, FieldTag: "yaml"},
+		{Name: FormatYAML, Start: "---yaml", End: "---", Unmarshal: yaml.Unmarshal, FieldTag: "yaml"},
+		{Name: FormatTOML, Start: "+++", End: "+++", Unmarshal: toml.Unmarshal, FieldTag: "toml"},
+		{Name: FormatTOML, Start: "---toml", End: "---", Unmarshal: toml.Unmarshal, FieldTag: "toml"},
+		{Name: FormatJSON, Start: ";;;", End: ";;;", Unmarshal: json.Unmarshal, FieldTag: "json"},
+		{Name: FormatJSON, Start: "---json", End: "---", Unmarshal: json.Unmarshal, FieldTag: "json"},
+		{
+			Name:            FormatJSON,
+			Start:           "{",
+			End:             "}",
+			Unmarshal:       json.Unmarshal,
+			FieldTag:        "json",
+			UnmarshalDelims: true,
+			RequiresNewLine: true,
+		},
+	}
+}
+
+// BuiltinFormat returns the first of DefaultFormats named name, or nil if there is none.
+func BuiltinFormat(name string) *Format {
+	for _, f := range DefaultFormats() {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
@@ -1,25 +1,212 @@
 package markdown
 
 import (
+	"bufio"
+	"bytes"
+	"errors"
 	"io"
 
-	"github.com/adrg/frontmatter"
+	"github.com/sushichan044/mdfm/internal/tree"
 )
 
+// Block describes the frontmatter block found at the beginning of a document.
+type Block struct {
+	// Format is the format the block was detected as.
+	Format *Format
+
+	// Raw is the data passed to Format.Unmarshal.
+	Raw []byte
+
+	// Line is the 1-based line number of the first line of Raw.
+	Line int
+
+	// BodyLine is the 1-based line number where the body starts.
+	BodyLine int
+
+	// Head is the input before Raw: leading empty lines and the opening delimiter line, if any.
+	Head []byte
+
+	// Tail is the input between Raw and the body: the closing delimiter line, if any,
+	// and the empty line required after it by Format.RequiresNewLine.
+	Tail []byte
+}
+
 // Parse parses the front matter from the given markdown content
 // and returns the parsed metadata and the rest of the content.
 //
-// The front matter is expected to be in YAML format and is unmarshalled into the
-// provided type T. The rest of the content is returned as a string.
+// The front matter format is detected from its delimiters (see DefaultFormats) and
+// is unmarshalled into the provided type T. The rest of the content is written to output.
 //
-// If the front matter is not present, FrontMatter will be empty.
-func Parse[T any](input io.Reader, output io.Writer, frontMatter *T) error {
-	// Parse the front matter and require it to be present
-	rest, err := frontmatter.Parse(input, frontMatter)
+// If the front matter is not present, FrontMatter will be empty and the returned Block is nil.
+// Errors returned by Unmarshal are reported as *DecodeError; any other error comes from
+// reading input or writing output.
+func Parse[T any](input io.Reader, output io.Writer, frontMatter *T) (*Block, error) {
+	return ParseWith(input, output, frontMatter, Options{})
+}
+
+// Options customizes ParseWith.
+type Options struct {
+	// Formats are the formats to detect, in order of precedence. DefaultFormats is used if empty.
+	Formats []*Format
+
+	// Decode decodes the front matter block. Unmarshal is used if nil.
+	Decode Decoder
+}
+
+// ParseWith is like Parse but detects and decodes the front matter block as configured by opts.
+// A *tree.OrderedMap is always decoded with DecodeOrdered to keep the keys in source order.
+// If output is nil, input is not read past the front matter block.
+func ParseWith(input io.Reader, output io.Writer, frontMatter any, opts Options) (*Block, error) {
+	formats, decode := opts.Formats, opts.Decode
+	if len(formats) == 0 {
+		formats = DefaultFormats()
+	}
+	if decode == nil {
+		decode = Unmarshal
+	}
+	if om, ok := frontMatter.(*tree.OrderedMap); ok {
+		decode = func(block *Block, _ any) error {
+			return DecodeOrdered(block, om)
+		}
+	}
+
+	p := newParser(input)
+
+	block, err := p.split(formats)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if block != nil {
+		if dErr := decode(block, frontMatter); dErr != nil {
+			return nil, dErr
+		}
+	}
+
+	if output == nil {
+		return block, nil
+	}
+	if _, wErr := output.Write(p.buf.Bytes()[p.end:]); wErr != nil {
+		return nil, wErr
+	}
+	if _, cErr := io.Copy(output, p.reader); cErr != nil {
+		return nil, cErr
+	}
+	return block, nil
+}
+
+// parser detects frontmatter blocks line by line, only reading as much input as needed.
+type parser struct {
+	reader *bufio.Reader
+	// buf holds everything read so far.
+	buf bytes.Buffer
+
+	// end is the offset in buf where the body starts.
+	end int
+}
+
+func newParser(r io.Reader) *parser {
+	return &parser{reader: bufio.NewReader(r)}
+}
+
+// split detects and extracts the frontmatter block.
+// It returns a nil Block when the input has no (complete) frontmatter block.
+func (p *parser) split(formats []*Format) (*Block, error) {
+	f, start, err := p.detect(formats)
+	if err != nil || f == nil {
+		return nil, err
+	}
+
+	for {
+		read := p.buf.Len()
+
+		line, atEOF, rErr := p.readLine()
+		if rErr != nil {
+			return nil, rErr
+		}
+
+		closed := line == f.End
+		// closeEnd is the offset after the closing delimiter line, before the empty line that may follow it.
+		closeEnd := p.buf.Len()
+		for closed && f.RequiresNewLine {
+			// The closing delimiter only counts when followed by an empty line.
+			// Otherwise, the following line is checked again as a closing delimiter.
+			if line, atEOF, rErr = p.readLine(); rErr != nil {
+				return nil, rErr
+			}
+			if line == "" {
+				break
+			}
+			closed = line == f.End
+			closeEnd = p.buf.Len()
+		}
+
+		if !closed {
+			if atEOF {
+				//nolint:nilnil // An unterminated block is not frontmatter, which is not an error.
+				return nil, nil
+			}
+			continue
+		}
+
+		if f.UnmarshalDelims {
+			read = closeEnd
+		}
+
+		data := p.buf.Bytes()
+		p.end = len(data)
+		return &Block{
+			Format:   f,
+			Raw:      bytes.Clone(data[start:read]),
+			Line:     lineAt(data, start),
+			BodyLine: lineAt(data, p.end),
+			Head:     bytes.Clone(data[:start]),
+			Tail:     bytes.Clone(data[read:p.end]),
+		}, nil
 	}
+}
+
+// detect skips leading empty lines and reports the format whose start delimiter matches
+// the first non-empty line, along with the offset where the frontmatter data starts.
+func (p *parser) detect(formats []*Format) (*Format, int, error) {
+	for {
+		read := p.buf.Len()
+
+		line, atEOF, err := p.readLine()
+		if err != nil || atEOF {
+			return nil, 0, err
+		}
+		if line == "" {
+			continue
+		}
+
+		for _, f := range formats {
+			if f.Start == line {
+				if !f.UnmarshalDelims {
+					read = p.buf.Len()
+				}
+				return f, read, nil
+			}
+		}
+
+		return nil, 0, nil
+	}
+}
+
+// readLine reads the next line into buf and returns it without surrounding white space.
+func (p *parser) readLine() (string, bool, error) {
+	line, err := p.reader.ReadBytes('\n')
+
+	atEOF := errors.Is(err, io.EOF)
+	if err != nil && !atEOF {
+		return "", false, err
+	}
+
+	p.buf.Write(line)
+	return string(bytes.TrimSpace(line)), atEOF, nil
+}
 
-	_, err = output.Write(rest)
-	return err
+// lineAt returns the 1-based line number of the given byte offset in data.
+func lineAt(data []byte, offset int) int {
+	return bytes.Count(data[:offset], []byte("\n")) + 1
 }
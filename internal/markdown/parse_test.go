@@ -1,6 +1,8 @@
 package markdown_test
 
 import (
+	"errors"
+	"io"
 	"strings"
 	"testing"
 
@@ -81,7 +83,7 @@ Content`,
 			defer output.Reset()
 
 			var meta testMetadata
-			err := markdown.Parse[testMetadata](strings.NewReader(tt.input), &output, &meta)
+			_, err := markdown.Parse[testMetadata](strings.NewReader(tt.input), &output, &meta)
 			if tt.expectError {
 				require.Error(t, err)
 			} else {
@@ -101,7 +103,7 @@ Content`
 
 		var output strings.Builder
 		var meta testMetadata
-		err := markdown.Parse[testMetadata](strings.NewReader(content), &output, &meta)
+		_, err := markdown.Parse[testMetadata](strings.NewReader(content), &output, &meta)
 		require.NoError(t, err)
 		assert.Equal(t, "Original Title", meta.Title)
 
@@ -109,8 +111,172 @@ Content`
 		meta.Title = "Modified Title"
 
 		// Parse again and confirm the original values are preserved
-		err = markdown.Parse[testMetadata](strings.NewReader(content), &output, &meta)
+		_, err = markdown.Parse[testMetadata](strings.NewReader(content), &output, &meta)
 		require.NoError(t, err)
 		assert.Equal(t, "Original Title", meta.Title)
 	})
 }
+
+func TestParse_Formats(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		format       string
+		expectedLine int
+		bodyLine     int
+		expectedBody string
+	}{
+		{
+			name:         "yaml",
+			input:        "---\ntitle: Test Title\n---\nBody",
+			format:       markdown.FormatYAML,
+			expectedLine: 2,
+			bodyLine:     4,
+			expectedBody: "Body",
+		},
+		{
+			name:         "yaml with leading empty lines",
+			input:        "\n\n---yaml\ntitle: Test Title\n---\nBody",
+			format:       markdown.FormatYAML,
+			expectedLine: 4,
+			bodyLine:     6,
+			expectedBody: "Body",
+		},
+		{
+			name:         "toml",
+			input:        "+++\ntitle = \"Test Title\"\n+++\nBody",
+			format:       markdown.FormatTOML,
+			expectedLine: 2,
+			bodyLine:     4,
+			expectedBody: "Body",
+		},
+		{
+			name:         "json between delimiters",
+			input:        ";;;\n{\"title\": \"Test Title\"}\n;;;\nBody",
+			format:       markdown.FormatJSON,
+			expectedLine: 2,
+			bodyLine:     4,
+			expectedBody: "Body",
+		},
+		{
+			name:         "json object followed by an empty line",
+			input:        "{\n  \"title\": \"Test Title\"\n}\n\nBody",
+			format:       markdown.FormatJSON,
+			expectedLine: 1,
+			bodyLine:     5,
+			expectedBody: "Body",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output strings.Builder
+			var meta map[string]any
+
+			block, err := markdown.Parse(strings.NewReader(tt.input), &output, &meta)
+			require.NoError(t, err)
+			require.NotNil(t, block)
+
+			assert.Equal(t, tt.format, block.Format.Name)
+			assert.Equal(t, tt.expectedLine, block.Line)
+			assert.Equal(t, tt.bodyLine, block.BodyLine)
+			assert.Equal(t, "Test Title", meta["title"])
+			assert.Equal(t, tt.expectedBody, output.String())
+		})
+	}
+
+	t.Run("unterminated block is not frontmatter", func(t *testing.T) {
+		input := "---\ntitle: Test Title\nBody"
+
+		var output strings.Builder
+		var meta map[string]any
+		block, err := markdown.Parse(strings.NewReader(input), &output, &meta)
+		require.NoError(t, err)
+
+		assert.Nil(t, block)
+		assert.Nil(t, meta)
+		assert.Equal(t, input, output.String())
+	})
+}
+
+// failingReader fails every read, to check that input is not read.
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("read past the frontmatter")
+}
+
+func TestParseWith_NilOutput(t *testing.T) {
+	input := io.MultiReader(strings.NewReader("---\ntitle: Hello\n---\n"), failingReader{})
+
+	var meta testMetadata
+	block, err := markdown.ParseWith(input, nil, &meta, markdown.Options{})
+	require.NoError(t, err)
+	require.NotNil(t, block)
+	assert.Equal(t, 4, block.BodyLine)
+	assert.Equal(t, "Hello", meta.Title)
+}
+
+func TestParse_DecodeError(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		format         string
+		line           int
+		column         int
+		snippet        string
+		reasonContains string
+	}{
+		{
+			name:    "yaml syntax error",
+			input:   "\n---\ntitle: ok\ndescription: [unclosed\n---\nBody",
+			format:  markdown.FormatYAML,
+			line:    4,
+			snippet: "description: [unclosed",
+		},
+		{
+			name:           "yaml type error",
+			input:          "---\ntitle: ok\nversion: abc\n---\nBody",
+			format:         markdown.FormatYAML,
+			line:           3,
+			snippet:        "version: abc",
+			reasonContains: "cannot unmarshal",
+		},
+		{
+			name:    "toml syntax error",
+			input:   "+++\ntitle = \"ok\"\nversion = = 1\n+++\nBody",
+			format:  markdown.FormatTOML,
+			line:    3,
+			column:  11,
+			snippet: "version = = 1",
+		},
+		{
+			name:    "json syntax error",
+			input:   ";;;\n{\n  \"title\": \"ok\",\n  \"version\": x\n}\n;;;\nBody",
+			format:  markdown.FormatJSON,
+			line:    4,
+			column:  14,
+			snippet: `  "version": x`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output strings.Builder
+			var meta testMetadata
+
+			_, err := markdown.Parse(strings.NewReader(tt.input), &output, &meta)
+			require.Error(t, err)
+
+			var decodeErr *markdown.DecodeError
+			require.ErrorAs(t, err, &decodeErr)
+			assert.Equal(t, tt.format, decodeErr.Format)
+			assert.Equal(t, tt.line, decodeErr.Line)
+			assert.Equal(t, tt.column, decodeErr.Column)
+			assert.Equal(t, tt.snippet, decodeErr.Snippet)
+			assert.NotContains(t, decodeErr.Reason, "line ")
+			assert.Contains(t, decodeErr.Reason, tt.reasonContains)
+			assert.NotNil(t, decodeErr.Unwrap())
+		})
+	}
+}
@@ -0,0 +1,349 @@
+package markdown
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Position is a 1-based line and column in a document.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// KeyPositions returns where each key of the frontmatter block is defined, in document coordinates.
+//
+// Keys are identified by their path, joined with ".": nested mappings add their key and sequences
+// add the 0-based index of the item, e.g. "seo.description" or "authors.0.name".
+// Positions are only available for the built-in formats; nil is returned for other formats
+// or if the block cannot be parsed.
+func KeyPositions(block *Block) map[string]Position {
+	if block == nil {
+		return nil
+	}
+
+	var positions map[string]Position
+	switch block.Format.Name {
+	case FormatYAML:
+		positions = yamlPositions(block.Raw)
+	case FormatTOML:
+		positions = tomlPositions(block.Raw)
+	case FormatJSON:
+		positions = jsonPositions(block.Raw)
+	default:
+		return nil
+	}
+
+	for key, pos := range positions {
+		pos.Line += block.Line - 1
+		positions[key] = pos
+	}
+	return positions
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func yamlPositions(raw []byte) map[string]Position {
+	var root yaml.Node
+	if err := yaml.Unmarshal(raw, &root); err != nil {
+		return nil
+	}
+
+	positions := make(map[string]Position)
+	walkYAML(&root, "", positions)
+	return positions
+}
+
+func walkYAML(node *yaml.Node, prefix string, positions map[string]Position) {
+	//nolint:exhaustive // Scalars and aliases have no nested keys.
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			walkYAML(child, prefix, positions)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+			key := joinKey(prefix, keyNode.Value)
+			positions[key] = Position{Line: keyNode.Line, Column: keyNode.Column}
+			walkYAML(valueNode, key, positions)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			key := joinKey(prefix, strconv.Itoa(i))
+			positions[key] = Position{Line: item.Line, Column: item.Column}
+			walkYAML(item, key, positions)
+		}
+	}
+}
+
+func jsonPositions(raw []byte) map[string]Position {
+	positions := make(map[string]Position)
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if err := walkJSON(dec, raw, "", positions); err != nil {
+		return nil
+	}
+	return positions
+}
+
+func walkJSON(dec *json.Decoder, raw []byte, prefix string, positions map[string]Position) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	delim, ok := tok.(json.Delim)
+	if !ok || (delim != '{' && delim != '[') {
+		return nil
+	}
+
+	for i := 0; dec.More(); i++ {
+		pos := jsonTokenPosition(raw, dec.InputOffset())
+
+		key := strconv.Itoa(i)
+		if delim == '{' {
+			keyTok, kErr := dec.Token()
+			if kErr != nil {
+				return kErr
+			}
+			key, _ = keyTok.(string)
+		}
+
+		key = joinKey(prefix, key)
+		positions[key] = pos
+		if wErr := walkJSON(dec, raw, key, positions); wErr != nil {
+			return wErr
+		}
+	}
+
+	// Consume the closing delimiter.
+	_, err = dec.Token()
+	return err
+}
+
+// jsonTokenPosition returns the position of the next token after offset,
+// skipping white space and separators.
+func jsonTokenPosition(raw []byte, offset int64) Position {
+	off := int(offset)
+	for off < len(raw) && (raw[off] == ',' || raw[off] == ':' || unicode.IsSpace(rune(raw[off]))) {
+		off++
+	}
+	lineStart := bytes.LastIndexByte(raw[:off], '\n') + 1
+	return Position{Line: lineAt(raw, off), Column: off - lineStart + 1}
+}
+
+// tomlPositions finds TOML keys by scanning the block line by line, since the TOML decoder does not
+// report where keys are defined. The block is decoded first, so nil is returned if it is invalid.
+//
+// The scanner understands tables, arrays of tables, dotted and quoted keys, and the implicit tables
+// they define. It skips the content of multi-line strings, arrays and inline tables: the items of
+// arrays and the keys of inline tables are not recorded, only the key they are assigned to.
+func tomlPositions(raw []byte) map[string]Position {
+	var v map[string]any
+	if _, err := toml.Decode(string(raw), &v); err != nil {
+		return nil
+	}
+
+	s := &tomlScanner{positions: make(map[string]Position), arrays: make(map[string]int)}
+	var (
+		multiline    string
+		bracketDepth int
+	)
+	for i, line := range strings.Split(string(raw), "\n") {
+		if multiline != "" {
+			if strings.Contains(line, multiline) {
+				multiline = ""
+			}
+			continue
+		}
+		if bracketDepth > 0 {
+			bracketDepth += tomlBracketDelta(line)
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		pos := Position{Line: i + 1, Column: len(line) - len(strings.TrimLeftFunc(line, unicode.IsSpace)) + 1}
+
+		value := s.scanLine(trimmed, pos)
+		for _, delim := range []string{`"""`, `'''`} {
+			if strings.HasPrefix(value, delim) && !strings.Contains(value[len(delim):], delim) {
+				multiline = delim
+			}
+		}
+		bracketDepth = tomlBracketDelta(value)
+	}
+	return s.positions
+}
+
+// tomlScanner records the positions of the keys of a TOML document, see tomlPositions.
+type tomlScanner struct {
+	positions map[string]Position
+
+	// arrays counts the tables of each array of tables defined so far, by path.
+	arrays map[string]int
+
+	// table is the path of the current table.
+	table string
+}
+
+// scanLine records the key or table defined by a non-empty line and returns the value assigned
+// to the key, if any.
+func (s *tomlScanner) scanLine(trimmed string, pos Position) string {
+	switch {
+	case strings.HasPrefix(trimmed, "[["):
+		parts := splitTOMLKey(strings.TrimSuffix(strings.TrimPrefix(trimmed, "[["), "]]"))
+		if len(parts) == 0 {
+			return ""
+		}
+		array := s.define("", parts, pos, false)
+		s.arrays[array]++
+		s.table = joinKey(array, strconv.Itoa(s.arrays[array]-1))
+		s.positions[s.table] = pos
+		return ""
+	case strings.HasPrefix(trimmed, "["):
+		end := strings.LastIndex(trimmed, "]")
+		if end < 0 {
+			return ""
+		}
+		s.table = s.define("", splitTOMLKey(trimmed[1:end]), pos, true)
+		return ""
+	default:
+		eq := tomlIndexOutsideQuotes(trimmed, '=')
+		if eq < 0 {
+			return ""
+		}
+		s.define(s.table, splitTOMLKey(trimmed[:eq]), pos, true)
+		return strings.TrimSpace(trimmed[eq+1:])
+	}
+}
+
+// define records pos for the key at parts below base, and for the tables above it that have no
+// position yet. The key itself is only recorded again if overwrite is set. Parts that refer to an
+// array of tables refer to its last table. It returns the path of the key.
+func (s *tomlScanner) define(base string, parts []string, pos Position, overwrite bool) string {
+	key := base
+	for i, part := range parts {
+		if i > 0 {
+			if n, ok := s.arrays[key]; ok {
+				key = joinKey(key, strconv.Itoa(n-1))
+			}
+		}
+		key = joinKey(key, part)
+		if _, ok := s.positions[key]; !ok || (overwrite && i == len(parts)-1) {
+			s.positions[key] = pos
+		}
+	}
+	return key
+}
+
+// splitTOMLKey splits a (possibly dotted and quoted) TOML key into its parts.
+func splitTOMLKey(key string) []string {
+	var parts []string
+	for key = strings.TrimSpace(key); key != ""; {
+		var part string
+		if key[0] == '"' || key[0] == '\'' {
+			end := tomlQuoteEnd(key)
+			part = key[1:end]
+			if unquoted, err := strconv.Unquote(key[:min(end+1, len(key))]); err == nil && key[0] == '"' {
+				part = unquoted
+			}
+			key = key[min(end+1, len(key)):]
+		} else {
+			dot := strings.IndexByte(key, '.')
+			if dot < 0 {
+				dot = len(key)
+			}
+			part, key = strings.TrimSpace(key[:dot]), key[dot:]
+		}
+		parts = append(parts, part)
+
+		key = strings.TrimPrefix(strings.TrimSpace(key), ".")
+		key = strings.TrimSpace(key)
+	}
+	return parts
+}
+
+// tomlQuoteEnd returns the index of the quote that closes the string opened by s[0], or len(s)
+// if the string is not closed. Escaped quotes in basic strings do not close them.
+func tomlQuoteEnd(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[0] == '"' && s[i] == '\\':
+			i++
+		case s[i] == s[0]:
+			return i
+		}
+	}
+	return len(s)
+}
+
+// tomlIndexOutsideQuotes returns the index of the first c in s that is not inside a quoted string.
+func tomlIndexOutsideQuotes(s string, c byte) int {
+	var (
+		quote   byte
+		escaped bool
+	)
+	for i := range len(s) {
+		switch {
+		case escaped:
+			escaped = false
+		case quote == '"' && s[i] == '\\':
+			// Basic strings can contain escaped quotes, literal strings cannot.
+			escaped = true
+		case quote != 0:
+			if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case s[i] == c:
+			return i
+		}
+	}
+	return -1
+}
+
+// tomlBracketDelta returns how many more brackets and braces are opened than closed in s,
+// ignoring quoted strings and comments.
+func tomlBracketDelta(s string) int {
+	var (
+		delta   int
+		quote   byte
+		escaped bool
+	)
+	for i := range len(s) {
+		switch {
+		case escaped:
+			escaped = false
+		case quote == '"' && s[i] == '\\':
+			escaped = true
+		case quote != 0:
+			if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case s[i] == '#':
+			return delta
+		case s[i] == '[' || s[i] == '{':
+			delta++
+		case s[i] == ']' || s[i] == '}':
+			delta--
+		}
+	}
+	return delta
+}
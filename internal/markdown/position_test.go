@@ -0,0 +1,185 @@
+package markdown_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/mdfm/internal/markdown"
+)
+
+func parseBlock(t *testing.T, input string) *markdown.Block {
+	t.Helper()
+
+	var output strings.Builder
+	var meta map[string]any
+	block, err := markdown.Parse(strings.NewReader(input), &output, &meta)
+	require.NoError(t, err)
+	require.NotNil(t, block)
+	return block
+}
+
+func TestKeyPositions_YAML(t *testing.T) {
+	block := parseBlock(t, `
+---
+title: Hello
+seo:
+  description: Nested
+tags:
+  - go
+  - yaml
+authors:
+  - name: Alice
+---
+Body`)
+
+	assert.Equal(t, map[string]markdown.Position{
+		"title":           {Line: 3, Column: 1},
+		"seo":             {Line: 4, Column: 1},
+		"seo.description": {Line: 5, Column: 3},
+		"tags":            {Line: 6, Column: 1},
+		"tags.0":          {Line: 7, Column: 5},
+		"tags.1":          {Line: 8, Column: 5},
+		"authors":         {Line: 9, Column: 1},
+		"authors.0":       {Line: 10, Column: 5},
+		"authors.0.name":  {Line: 10, Column: 5},
+	}, markdown.KeyPositions(block))
+}
+
+func TestKeyPositions_TOML(t *testing.T) {
+	block := parseBlock(t, `+++
+title = "Hello"
+"quoted.key" = 1
+site.name = "mdfm"
+description = """
+not = a key
+"""
+tags = [
+  "a",
+]
+
+[seo]
+  description = "Nested"
+
+[[authors]]
+name = "Alice"
+
+[[authors]]
+name = "Bob"
++++
+Body`)
+
+	assert.Equal(t, map[string]markdown.Position{
+		"title":           {Line: 2, Column: 1},
+		"quoted.key":      {Line: 3, Column: 1},
+		"site":            {Line: 4, Column: 1},
+		"site.name":       {Line: 4, Column: 1},
+		"description":     {Line: 5, Column: 1},
+		"tags":            {Line: 8, Column: 1},
+		"seo":             {Line: 12, Column: 1},
+		"seo.description": {Line: 13, Column: 3},
+		"authors":         {Line: 15, Column: 1},
+		"authors.0":       {Line: 15, Column: 1},
+		"authors.0.name":  {Line: 16, Column: 1},
+		"authors.1":       {Line: 18, Column: 1},
+		"authors.1.name":  {Line: 19, Column: 1},
+	}, markdown.KeyPositions(block))
+}
+
+func TestKeyPositions_TOMLNested(t *testing.T) {
+	block := parseBlock(t, `+++
+[[fruits]]
+name = "apple"
+seo = { description = "Inline", "key = value" = 1 }
+tags = [
+  "a = b",
+  ["[nested]"],
+]
+notes = '''
+[not.a.table]
+'''
+
+[fruits.physical]
+color = "red"
+
+[[fruits.varieties]]
+name = "fuji"
+
+[[fruits]]
+name = "banana"
+
+[a.b.c]
+d = 1
++++
+Body`)
+
+	assert.Equal(t, map[string]markdown.Position{
+		"fruits":                    {Line: 2, Column: 1},
+		"fruits.0":                  {Line: 2, Column: 1},
+		"fruits.0.name":             {Line: 3, Column: 1},
+		"fruits.0.seo":              {Line: 4, Column: 1},
+		"fruits.0.tags":             {Line: 5, Column: 1},
+		"fruits.0.notes":            {Line: 9, Column: 1},
+		"fruits.0.physical":         {Line: 13, Column: 1},
+		"fruits.0.physical.color":   {Line: 14, Column: 1},
+		"fruits.0.varieties":        {Line: 16, Column: 1},
+		"fruits.0.varieties.0":      {Line: 16, Column: 1},
+		"fruits.0.varieties.0.name": {Line: 17, Column: 1},
+		"fruits.1":                  {Line: 19, Column: 1},
+		"fruits.1.name":             {Line: 20, Column: 1},
+		"a":                         {Line: 22, Column: 1},
+		"a.b":                       {Line: 22, Column: 1},
+		"a.b.c":                     {Line: 22, Column: 1},
+		"a.b.c.d":                   {Line: 23, Column: 1},
+	}, markdown.KeyPositions(block))
+}
+
+func TestKeyPositions_TOMLEscapedQuotes(t *testing.T) {
+	block := parseBlock(t, `+++
+title = "a \" [b"
+path = 'C:\'
+"say \"hi\"" = true
+
+[seo]
+desc = "{ \"x\" = 1"
++++
+Body`)
+
+	assert.Equal(t, map[string]markdown.Position{
+		"title":    {Line: 2, Column: 1},
+		"path":     {Line: 3, Column: 1},
+		`say "hi"`: {Line: 4, Column: 1},
+		"seo":      {Line: 6, Column: 1},
+		"seo.desc": {Line: 7, Column: 1},
+	}, markdown.KeyPositions(block))
+}
+
+func TestKeyPositions_InvalidTOML(t *testing.T) {
+	block := &markdown.Block{Format: markdown.BuiltinFormat(markdown.FormatTOML), Raw: []byte("a = = 1\n"), Line: 2}
+	assert.Nil(t, markdown.KeyPositions(block))
+}
+
+func TestKeyPositions_JSON(t *testing.T) {
+	block := parseBlock(t, `{
+  "title": "Hello",
+  "seo": {"description": "Nested"},
+  "tags": ["a", "b"]
+}
+
+Body`)
+
+	assert.Equal(t, map[string]markdown.Position{
+		"title":           {Line: 2, Column: 3},
+		"seo":             {Line: 3, Column: 3},
+		"seo.description": {Line: 3, Column: 11},
+		"tags":            {Line: 4, Column: 3},
+		"tags.0":          {Line: 4, Column: 12},
+		"tags.1":          {Line: 4, Column: 17},
+	}, markdown.KeyPositions(block))
+}
+
+func TestKeyPositions_NoBlock(t *testing.T) {
+	assert.Nil(t, markdown.KeyPositions(nil))
+}
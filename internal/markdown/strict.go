@@ -0,0 +1,202 @@
+package markdown
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// UnknownKeys reports the keys of the frontmatter block that do not match any field of t,
+// the type the block is decoded into. Keys are identified by their path as in KeyPositions.
+//
+// Field names are read from the struct tag named tag, which defaults to Format.FieldTag when empty.
+// Only keys that the decoder silently ignores are reported: maps and interfaces accept
+// any key, and types that implement their own unmarshaling are not inspected.
+// Nothing is reported when no tag is known.
+//
+// The strict modes of the decoders are not used since they cannot read the unified tag, and
+// yaml.v2 and encoding/json report keys without their path or stop at the first one. The rules
+// they match keys with are replicated instead, see fieldRules.
+func UnknownKeys(block *Block, t reflect.Type, tag string) ([]string, error) {
+	if tag == "" && block != nil {
+		tag = block.Format.FieldTag
+	}
+	if block == nil || tag == "" {
+		return nil, nil
+	}
+
+	generic, err := Generic(block)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &keyChecker{rules: newFieldRules(tag)}
+	c.check("", reflect.ValueOf(generic), t)
+	slices.Sort(c.unknown)
+	return c.unknown, nil
+}
+
+// fieldRules describes how a decoder matches keys to struct fields.
+type fieldRules struct {
+	tag string
+
+	// yaml.v2 matches keys exactly, defaults to the lowercased field name and only
+	// flattens embedded structs marked with ",inline". The other decoders match keys
+	// case-insensitively and flatten untagged embedded structs.
+	yamlStyle bool
+}
+
+func newFieldRules(tag string) fieldRules {
+	return fieldRules{tag: tag, yamlStyle: tag == "yaml"}
+}
+
+// structFields are the keys accepted by a struct type.
+type structFields struct {
+	byName map[string]reflect.Type
+
+	// inlineMap is set when a ",inline" map collects the remaining keys.
+	inlineMap bool
+}
+
+func (r fieldRules) fields(t reflect.Type) *structFields {
+	fields := &structFields{byName: make(map[string]reflect.Type)}
+	r.collect(t, fields, map[reflect.Type]bool{})
+	return fields
+}
+
+func (r fieldRules) collect(t reflect.Type, fields *structFields, visited map[reflect.Type]bool) {
+	if visited[t] {
+		return
+	}
+	visited[t] = true
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get(r.tag)
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if r.isInline(f, name, opts) {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			switch ft.Kind() { //nolint:exhaustive // Other kinds are regular fields.
+			case reflect.Struct:
+				r.collect(ft, fields, visited)
+				continue
+			case reflect.Map:
+				fields.inlineMap = true
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+			if r.yamlStyle {
+				name = strings.ToLower(name)
+			}
+		}
+		if _, exists := fields.byName[name]; !exists {
+			fields.byName[name] = f.Type
+		}
+	}
+}
+
+func (r fieldRules) isInline(f reflect.StructField, name, opts string) bool {
+	if r.yamlStyle {
+		return slices.Contains(strings.Split(opts, ","), "inline")
+	}
+	return f.Anonymous && name == ""
+}
+
+func (r fieldRules) lookup(fields *structFields, key string) (reflect.Type, bool) {
+	if t, ok := fields.byName[key]; ok {
+		return t, true
+	}
+	if r.yamlStyle {
+		return nil, false
+	}
+	for name, t := range fields.byName {
+		if strings.EqualFold(name, key) {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+type keyChecker struct {
+	rules   fieldRules
+	unknown []string
+}
+
+// check walks the generically decoded value v alongside the target type t.
+func (c *keyChecker) check(path string, v reflect.Value, t reflect.Type) {
+	for v.IsValid() && v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if hasCustomUnmarshaler(t) {
+		return
+	}
+
+	switch t.Kind() { //nolint:exhaustive // Scalars have no keys.
+	case reflect.Struct:
+		if v.Kind() != reflect.Map {
+			return
+		}
+		fields := c.rules.fields(t)
+		iter := v.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			keyPath := joinKey(path, key)
+			ft, ok := c.rules.lookup(fields, key)
+			if !ok {
+				if !fields.inlineMap {
+					c.unknown = append(c.unknown, keyPath)
+				}
+				continue
+			}
+			c.check(keyPath, iter.Value(), ft)
+		}
+	case reflect.Map:
+		if v.Kind() != reflect.Map {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			c.check(joinKey(path, fmt.Sprint(iter.Key().Interface())), iter.Value(), t.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Kind() != reflect.Slice {
+			return
+		}
+		for i := range v.Len() {
+			c.check(joinKey(path, strconv.Itoa(i)), v.Index(i), t.Elem())
+		}
+	}
+}
+
+// hasCustomUnmarshaler reports whether values of t decode themselves,
+// in which case their keys cannot be checked.
+func hasCustomUnmarshaler(t reflect.Type) bool {
+	pt := reflect.PointerTo(t)
+	for _, method := range []string{"UnmarshalYAML", "UnmarshalTOML", "UnmarshalJSON", "UnmarshalText"} {
+		if _, ok := pt.MethodByName(method); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,248 @@
+package markdown_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/sushichan044/mdfm/internal/markdown"
+)
+
+type strictSEO struct {
+	Description string `yaml:"description" toml:"description" json:"description"`
+}
+
+type strictBase struct {
+	Draft bool `yaml:"draft" toml:"draft" json:"draft"`
+}
+
+type strictMetadata struct {
+	strictBase `yaml:",inline"`
+
+	Title   string            `yaml:"title" toml:"title" json:"title"`
+	Date    time.Time         `yaml:"date" toml:"date" json:"date"`
+	SEO     strictSEO         `yaml:"seo" toml:"seo" json:"seo"`
+	Authors []strictSEO       `yaml:"authors" toml:"authors" json:"authors"`
+	Extra   map[string]any    `yaml:"extra" toml:"extra" json:"extra"`
+	Labels  map[string]string `yaml:"labels" toml:"labels" json:"labels"`
+	Ignored string            `yaml:"-" toml:"-" json:"-"`
+	Summary string
+}
+
+func TestUnknownKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name: "yaml",
+			input: `---
+title: Hello
+draft: true
+date: 2024-01-01T00:00:00Z
+titel: typo
+summary: lowercased field name
+Summary: yaml matches exactly
+ignored: skipped field
+seo:
+  description: ok
+  descripton: typo
+authors:
+  - description: ok
+  - nmae: typo
+extra:
+  anything: goes
+---
+`,
+			expected: []string{"Summary", "authors.1.nmae", "ignored", "seo.descripton", "titel"},
+		},
+		{
+			name: "toml",
+			input: `+++
+title = "Hello"
+draft = true
+TITLE = "case-insensitive"
+summary = "case-insensitive"
+titel = "typo"
+
+[seo]
+descripton = "typo"
+
+[[authors]]
+nmae = "typo"
++++
+`,
+			expected: []string{"authors.0.nmae", "seo.descripton", "titel"},
+		},
+		{
+			name: "json",
+			input: `;;;
+{"title": "Hello", "Draft": true, "titel": "typo", "seo": {"descripton": "typo"}, "labels": {"a": "b"}}
+;;;
+`,
+			expected: []string{"seo.descripton", "titel"},
+		},
+		{
+			name:     "no unknown keys",
+			input:    "---\ntitle: Hello\n---\n",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block := parseBlock(t, tt.input)
+
+			unknown, err := markdown.UnknownKeys(block, reflect.TypeFor[strictMetadata](), "")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, unknown)
+		})
+	}
+
+	t.Run("maps and interfaces accept any key", func(t *testing.T) {
+		block := parseBlock(t, "---\ntitle: Hello\nseo:\n  anything: goes\n---\n")
+
+		for _, typ := range []reflect.Type{reflect.TypeFor[map[string]any](), reflect.TypeFor[any]()} {
+			unknown, err := markdown.UnknownKeys(block, typ, "")
+			require.NoError(t, err)
+			assert.Empty(t, unknown)
+		}
+	})
+
+	t.Run("no block", func(t *testing.T) {
+		unknown, err := markdown.UnknownKeys(nil, reflect.TypeFor[strictMetadata](), "")
+		require.NoError(t, err)
+		assert.Empty(t, unknown)
+	})
+}
+
+// StrictBase is exported so that yaml.v2 can decode it when it is embedded without ",inline".
+type StrictBase struct {
+	Draft bool `yaml:"draft" toml:"draft" json:"draft"`
+}
+
+// strictEmbedded embeds StrictBase without a tag: yaml.v2 decodes it from a "strictbase" key,
+// while the TOML and JSON decoders flatten its fields.
+type strictEmbedded struct {
+	StrictBase
+
+	Title string    `yaml:"title" toml:"title" json:"title"`
+	SEO   strictSEO `yaml:"seo" toml:"seo" json:"seo"`
+}
+
+// strictInlined embeds StrictBase with ",inline", which flattens its fields for yaml.v2 too.
+type strictInlined struct {
+	StrictBase `yaml:",inline"`
+
+	Title string `yaml:"title" toml:"title" json:"title"`
+}
+
+// decoderUnknownKeys returns the last part of the keys rejected by the strict mode of the decoder of the block.
+// encoding/json only reports the first unknown key.
+func decoderUnknownKeys(t *testing.T, block *markdown.Block, v any) []string {
+	t.Helper()
+
+	var keys []string
+	switch block.Format.Name {
+	case markdown.FormatYAML:
+		if err := yaml.UnmarshalStrict(block.Raw, v); err != nil {
+			for _, m := range regexp.MustCompile(`field (\S+) not found`).FindAllStringSubmatch(err.Error(), -1) {
+				keys = append(keys, m[1])
+			}
+		}
+	case markdown.FormatTOML:
+		meta, err := toml.Decode(string(block.Raw), v)
+		require.NoError(t, err)
+		for _, key := range meta.Undecoded() {
+			keys = append(keys, key[len(key)-1])
+		}
+	case markdown.FormatJSON:
+		dec := json.NewDecoder(bytes.NewReader(block.Raw))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(v); err != nil {
+			for _, m := range regexp.MustCompile(`unknown field "([^"]+)"`).FindAllStringSubmatch(err.Error(), -1) {
+				keys = append(keys, m[1])
+			}
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// TestUnknownKeys_MatchesDecoders checks that UnknownKeys rejects the same keys as the strict modes of the decoders.
+// The JSON inputs have at most one unknown key, since encoding/json stops at the first one.
+func TestUnknownKeys_MatchesDecoders(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		target func() any
+		want   []string
+	}{
+		{
+			name:   "yaml embedded struct is a key",
+			input:  "---\ntitle: a\ndraft: true\nstrictbase:\n  draft: true\nseo:\n  Description: b\n---\n",
+			target: func() any { return new(strictEmbedded) },
+			want:   []string{"Description", "draft"},
+		},
+		{
+			name:   "yaml inline struct is flattened",
+			input:  "---\ntitle: a\ndraft: true\nTitle: b\n---\n",
+			target: func() any { return new(strictInlined) },
+			want:   []string{"Title"},
+		},
+		{
+			name:   "toml embedded struct is flattened and keys are case-insensitive",
+			input:  "+++\nTITLE = \"a\"\nDraft = true\nStrictBase = 1\n[seo]\nDESCRIPTION = \"b\"\ndescripton = \"c\"\n+++\n",
+			target: func() any { return new(strictEmbedded) },
+			want:   []string{"StrictBase", "descripton"},
+		},
+		{
+			name:   "json keys are case-insensitive",
+			input:  ";;;\n{\"TITLE\": \"a\", \"DRAFT\": true, \"Seo\": {\"Description\": \"b\"}}\n;;;\n",
+			target: func() any { return new(strictEmbedded) },
+			want:   nil,
+		},
+		{
+			name:   "json embedded struct is flattened",
+			input:  ";;;\n{\"title\": \"a\", \"draft\": true, \"StrictBase\": {}}\n;;;\n",
+			target: func() any { return new(strictEmbedded) },
+			want:   []string{"StrictBase"},
+		},
+		{
+			name:   "json nested unknown key",
+			input:  ";;;\n{\"seo\": {\"descripton\": \"b\"}}\n;;;\n",
+			target: func() any { return new(strictInlined) },
+			want:   []string{"seo"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block := parseBlock(t, tt.input)
+			target := tt.target()
+
+			unknown, err := markdown.UnknownKeys(block, reflect.TypeOf(target), "")
+			require.NoError(t, err)
+
+			var lastParts []string
+			for _, key := range unknown {
+				lastParts = append(lastParts, key[strings.LastIndex(key, ".")+1:])
+			}
+			slices.Sort(lastParts)
+
+			assert.Equal(t, tt.want, lastParts)
+			assert.Equal(t, tt.want, decoderUnknownKeys(t, block, target))
+		})
+	}
+}
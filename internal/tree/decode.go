@@ -0,0 +1,410 @@
+package tree
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldError is returned by Decode when a value of the tree cannot be stored in its destination.
+type FieldError struct {
+	// Path is the path of the value, joined with "." as in "seo.date" or "authors.0.name".
+	// It is empty for the root of the tree.
+	Path string
+
+	// Err describes the problem.
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// dateLayouts are the layouts accepted when decoding a string into a time.Time.
+// Times without a zone are interpreted as UTC.
+//
+//nolint:gochecknoglobals // Read-only table.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	time.DateOnly,
+}
+
+//nolint:gochecknoglobals // Read-only type descriptors.
+var (
+	timeType            = reflect.TypeFor[time.Time]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// Decode stores the tree src, as produced by Normalize, in the value pointed to by out.
+//
+// Struct fields are matched by the name given in the struct tag named tag, or by the field name
+// if the tag is absent; keys are compared case-insensitively if there is no exact match.
+// Fields tagged "-" are skipped and untagged embedded structs have their fields promoted.
+//
+// Conversions are the same regardless of the source format:
+//
+//   - integers can be stored in any integer or float type, and floats with an integral value
+//     in integer types, as long as they do not overflow,
+//   - time.Time accepts datetimes and strings in RFC 3339 format, with or without a zone
+//     (UTC is assumed), or a plain date such as "2006-01-02"; TOML local dates and datetimes
+//     are interpreted as UTC too, so that a date decodes the same from every format,
+//   - strings accept datetimes, which are formatted in RFC 3339 format (TOML local values keep
+//     their local form), and booleans and numbers, so that values such as "title: 2024" or
+//     "version: 1.0" can be read as text. Numbers are formatted as in Go, with ".0" appended
+//     to integral floats: "version: 1.50" becomes "1.5",
+//   - types implementing encoding.TextUnmarshaler accept strings.
+//
+// Any other mismatch is reported as *FieldError.
+func Decode(src any, out any, tag string) error {
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("tree: Decode requires a non-nil pointer")
+	}
+	return decoder{tag: tag}.decode("", src, rv.Elem())
+}
+
+type decoder struct {
+	tag string
+}
+
+func (d decoder) decode(path string, src any, dst reflect.Value) error {
+	if src == nil {
+		dst.SetZero()
+		return nil
+	}
+
+	if dst.Kind() == reflect.Pointer {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return d.decode(path, src, dst.Elem())
+	}
+
+	if dst.Type() == timeType {
+		return decodeTime(path, src, dst)
+	}
+	if s, ok := src.(string); ok && reflect.PointerTo(dst.Type()).Implements(textUnmarshalerType) {
+		if err := dst.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return &FieldError{Path: path, Err: err}
+		}
+		return nil
+	}
+
+	switch dst.Kind() { //nolint:exhaustive // Other kinds cannot hold frontmatter values.
+	case reflect.Interface:
+		if dst.NumMethod() > 0 {
+			return mismatch(path, src, dst)
+		}
+		dst.Set(reflect.ValueOf(src))
+		return nil
+	case reflect.String:
+		return decodeString(path, src, dst)
+	case reflect.Bool:
+		b, ok := src.(bool)
+		if !ok {
+			return mismatch(path, src, dst)
+		}
+		dst.SetBool(b)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return decodeInteger(path, src, dst)
+	case reflect.Float32, reflect.Float64:
+		return decodeFloat(path, src, dst)
+	case reflect.Slice, reflect.Array:
+		return d.decodeList(path, src, dst)
+	case reflect.Map:
+		return d.decodeMap(path, src, dst)
+	case reflect.Struct:
+		return d.decodeStruct(path, src, dst)
+	default:
+		return mismatch(path, src, dst)
+	}
+}
+
+// ParseTime parses s as a date or datetime in one of the layouts Decode accepts for time.Time fields.
+func ParseTime(s string) (time.Time, bool) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func decodeTime(path string, src any, dst reflect.Value) error {
+	switch v := src.(type) {
+	case time.Time:
+		dst.Set(reflect.ValueOf(wallClock(v)))
+		return nil
+	case string:
+		if t, ok := ParseTime(v); ok {
+			dst.Set(reflect.ValueOf(t))
+			return nil
+		}
+		return &FieldError{Path: path, Err: fmt.Errorf("cannot parse %q as a date", v)}
+	default:
+		return mismatch(path, src, dst)
+	}
+}
+
+func decodeString(path string, src any, dst reflect.Value) error {
+	switch v := src.(type) {
+	case string:
+		dst.SetString(v)
+	case time.Time:
+		dst.SetString(FormatTime(v))
+	case bool:
+		dst.SetString(strconv.FormatBool(v))
+	case int64:
+		dst.SetString(strconv.FormatInt(v, 10))
+	case float64:
+		f := strconv.FormatFloat(v, 'g', -1, 64)
+		if !strings.ContainsAny(f, ".eEIN") {
+			// Keep numbers such as "version: 1.0" recognizable as floats.
+			f += ".0"
+		}
+		dst.SetString(f)
+	default:
+		return mismatch(path, src, dst)
+	}
+	return nil
+}
+
+func decodeInteger(path string, src any, dst reflect.Value) error {
+	var i int64
+	switch v := src.(type) {
+	case int64:
+		i = v
+	case float64:
+		if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+			return mismatch(path, src, dst)
+		}
+		i = int64(v)
+	default:
+		return mismatch(path, src, dst)
+	}
+
+	if dst.CanInt() {
+		if dst.OverflowInt(i) {
+			return overflow(path, src, dst)
+		}
+		dst.SetInt(i)
+		return nil
+	}
+	if i < 0 || dst.OverflowUint(uint64(i)) {
+		return overflow(path, src, dst)
+	}
+	dst.SetUint(uint64(i))
+	return nil
+}
+
+func decodeFloat(path string, src any, dst reflect.Value) error {
+	var f float64
+	switch v := src.(type) {
+	case int64:
+		f = float64(v)
+	case float64:
+		f = v
+	default:
+		return mismatch(path, src, dst)
+	}
+
+	if dst.OverflowFloat(f) {
+		return overflow(path, src, dst)
+	}
+	dst.SetFloat(f)
+	return nil
+}
+
+func (d decoder) decodeList(path string, src any, dst reflect.Value) error {
+	list, ok := src.([]any)
+	if !ok {
+		return mismatch(path, src, dst)
+	}
+
+	if dst.Kind() == reflect.Slice {
+		dst.Set(reflect.MakeSlice(dst.Type(), len(list), len(list)))
+	} else {
+		dst.SetZero()
+	}
+
+	for i, item := range list[:min(len(list), dst.Len())] {
+		if err := d.decode(joinPath(path, strconv.Itoa(i)), item, dst.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d decoder) decodeMap(path string, src any, dst reflect.Value) error {
+	m, ok := src.(map[string]any)
+	if !ok {
+		return mismatch(path, src, dst)
+	}
+	if dst.Type().Key().Kind() != reflect.String {
+		return &FieldError{Path: path, Err: fmt.Errorf("unsupported map key type %s", dst.Type().Key())}
+	}
+
+	if dst.IsNil() {
+		dst.Set(reflect.MakeMapWithSize(dst.Type(), len(m)))
+	}
+	for key, value := range m {
+		elem := reflect.New(dst.Type().Elem()).Elem()
+		if err := d.decode(joinPath(path, key), value, elem); err != nil {
+			return err
+		}
+		dst.SetMapIndex(reflect.ValueOf(key).Convert(dst.Type().Key()), elem)
+	}
+	return nil
+}
+
+func (d decoder) decodeStruct(path string, src any, dst reflect.Value) error {
+	m, ok := src.(map[string]any)
+	if !ok {
+		return mismatch(path, src, dst)
+	}
+
+	fields := d.fields(dst.Type())
+	for key, value := range m {
+		f, found := lookupField(fields, key)
+		if !found {
+			continue
+		}
+		if err := d.decode(joinPath(path, key), value, fieldByIndex(dst, f.index)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// field is a struct field that can be decoded into.
+type field struct {
+	name  string
+	index []int
+}
+
+// fields lists the fields of the struct type t, including promoted fields.
+func (d decoder) fields(t reflect.Type) []field {
+	var fields []field
+	d.collect(t, nil, &fields, map[reflect.Type]bool{})
+	return fields
+}
+
+func (d decoder) collect(t reflect.Type, index []int, fields *[]field, visited map[reflect.Type]bool) {
+	if visited[t] {
+		return
+	}
+	visited[t] = true
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get(d.tag)
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		fieldIndex := append(append([]int(nil), index...), i)
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer && f.IsExported() {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				d.collect(ft, fieldIndex, fields, visited)
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		*fields = append(*fields, field{name: name, index: fieldIndex})
+	}
+}
+
+// lookupField finds the field for key, preferring an exact match over a case-insensitive one.
+// Shallower fields take precedence over promoted ones.
+func lookupField(fields []field, key string) (field, bool) {
+	for _, f := range fields {
+		if f.name == key {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, key) {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex but allocates nil embedded pointers.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func mismatch(path string, src any, dst reflect.Value) error {
+	return &FieldError{Path: path, Err: fmt.Errorf("cannot decode %s into %s", describe(src), dst.Type())}
+}
+
+func overflow(path string, src any, dst reflect.Value) error {
+	return &FieldError{Path: path, Err: fmt.Errorf("%s overflows %s", describe(src), dst.Type())}
+}
+
+// describe names the kind of a tree value for error messages.
+func describe(v any) string {
+	switch v := v.(type) {
+	case string:
+		return "string " + strconv.Quote(v)
+	case bool:
+		return "boolean " + strconv.FormatBool(v)
+	case int64:
+		return "integer " + strconv.FormatInt(v, 10)
+	case float64:
+		return "number " + strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		return "datetime " + v.Format(time.RFC3339Nano)
+	case map[string]any:
+		return "mapping"
+	case []any:
+		return "list"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
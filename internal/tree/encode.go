@@ -0,0 +1,272 @@
+package tree
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+//nolint:gochecknoglobals // Read-only type descriptor.
+var orderedMapType = reflect.TypeFor[OrderedMap]()
+
+// yamlMarshaler is the Marshaler interface of gopkg.in/yaml.v2.
+type yamlMarshaler interface {
+	MarshalYAML() (any, error)
+}
+
+// Encode converts v into a tree like the ones returned by Normalize, with mappings stored as
+// *OrderedMap. It is the inverse of Decode and of the format decoders: struct fields are named
+// after the struct tag named tag ("yaml", "toml", "json" or "mdfm") and keep their declaration
+// order, while the keys of Go maps are sorted.
+//
+// The "omitempty" and "omitzero" tag options are honored, as well as ",inline" for the "yaml"
+// tag and promoted fields of untagged embedded structs for the other tags. time.Time values
+// are kept as is, and types implementing encoding.TextMarshaler are encoded as strings.
+func Encode(v any, tag string) (any, error) {
+	return encoder{tag: tag}.encode(reflect.ValueOf(v))
+}
+
+type encoder struct {
+	tag string
+}
+
+func (e encoder) encode(v reflect.Value) (any, error) {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return nil, nil //nolint:nilnil // A nil value is encoded as null.
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil, nil //nolint:nilnil // A nil value is encoded as null.
+	}
+
+	if encoded, ok, err := e.encodeCustom(v); ok {
+		return encoded, err
+	}
+
+	switch v.Kind() { //nolint:exhaustive // Other kinds cannot be represented in frontmatter.
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return encodeScalar(v), nil
+	case reflect.Slice, reflect.Array:
+		list := make([]any, v.Len())
+		for i := range v.Len() {
+			item, err := e.encode(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			list[i] = item
+		}
+		return list, nil
+	case reflect.Map:
+		return e.encodeMap(v)
+	case reflect.Struct:
+		return e.encodeStruct(v)
+	default:
+		return nil, fmt.Errorf("cannot encode %s", v.Type())
+	}
+}
+
+// encodeCustom encodes the values that control their own representation.
+func (e encoder) encodeCustom(v reflect.Value) (any, bool, error) {
+	switch {
+	case v.Type() == timeType:
+		return v.Interface(), true, nil
+	case v.Type() == orderedMapType:
+		om := v.Interface().(OrderedMap) //nolint:forcetypeassert // Checked above.
+		encoded := NewOrderedMap()
+		for key, value := range om.All() {
+			item, err := e.encode(reflect.ValueOf(value))
+			if err != nil {
+				return nil, true, err
+			}
+			encoded.Set(key, item)
+		}
+		return encoded, true, nil
+	}
+
+	if m, ok := asInterface[yamlMarshaler](v); ok && e.tag == "yaml" {
+		out, err := m.MarshalYAML()
+		if err != nil {
+			return nil, true, err
+		}
+		encoded, err := e.encode(reflect.ValueOf(out))
+		return encoded, true, err
+	}
+	if m, ok := asInterface[json.Marshaler](v); ok && e.tag == "json" {
+		data, err := m.MarshalJSON()
+		if err != nil {
+			return nil, true, err
+		}
+		var om OrderedMap
+		if json.Unmarshal(data, &om) == nil {
+			return &om, true, nil
+		}
+		var out any
+		err = json.Unmarshal(data, &out)
+		return Normalize(out), true, err
+	}
+	if m, ok := asInterface[encoding.TextMarshaler](v); ok {
+		text, err := m.MarshalText()
+		return string(text), true, err
+	}
+	return nil, false, nil
+}
+
+// asInterface returns v as I if v, or a pointer to v, implements I.
+func asInterface[I any](v reflect.Value) (I, bool) {
+	if i, ok := v.Interface().(I); ok {
+		return i, true
+	}
+	if v.CanAddr() {
+		if i, ok := v.Addr().Interface().(I); ok {
+			return i, true
+		}
+	}
+	var zero I
+	return zero, false
+}
+
+func encodeScalar(v reflect.Value) any {
+	switch v.Kind() { //nolint:exhaustive // Only called for scalar kinds.
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.String:
+		return v.String()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	default:
+		return Normalize(v.Interface())
+	}
+}
+
+func (e encoder) encodeMap(v reflect.Value) (*OrderedMap, error) {
+	keys := v.MapKeys()
+	names := make(map[string]reflect.Value, len(keys))
+	for _, key := range keys {
+		names[fmt.Sprint(key.Interface())] = key
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	slices.Sort(sorted)
+
+	om := NewOrderedMap()
+	for _, name := range sorted {
+		item, err := e.encode(v.MapIndex(names[name]))
+		if err != nil {
+			return nil, err
+		}
+		om.Set(name, item)
+	}
+	return om, nil
+}
+
+func (e encoder) encodeStruct(v reflect.Value) (any, error) {
+	om := NewOrderedMap()
+	if err := e.encodeFields(v, om); err != nil {
+		return nil, err
+	}
+	return om, nil
+}
+
+func (e encoder) encodeFields(v reflect.Value, om *OrderedMap) error {
+	yamlStyle := e.tag == "yaml"
+
+	for i := range v.NumField() {
+		f := v.Type().Field(i)
+		tag := f.Tag.Get(e.tag)
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		options := strings.Split(opts, ",")
+		fv := v.Field(i)
+
+		inline := slices.Contains(options, "inline")
+		if !yamlStyle {
+			inline = f.Anonymous && name == ""
+		}
+		if inline {
+			if handled, err := e.encodeInline(fv, om); handled {
+				if err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if !f.IsExported() || omitted(fv, options) {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+			if yamlStyle {
+				name = strings.ToLower(name)
+			}
+		}
+
+		item, err := e.encode(fv)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		om.Set(name, item)
+	}
+	return nil
+}
+
+// encodeInline adds the fields of an inlined struct or map to om.
+func (e encoder) encodeInline(v reflect.Value, om *OrderedMap) (bool, error) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return v.Type().Elem().Kind() == reflect.Struct, nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() { //nolint:exhaustive // Other kinds are regular fields.
+	case reflect.Struct:
+		return true, e.encodeFields(v, om)
+	case reflect.Map:
+		encoded, err := e.encodeMap(v)
+		if err != nil {
+			return true, err
+		}
+		for key, value := range encoded.All() {
+			om.Set(key, value)
+		}
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// omitted reports whether a field with the given tag options is left out for the value v.
+func omitted(v reflect.Value, options []string) bool {
+	if slices.Contains(options, "omitzero") && v.IsZero() {
+		return true
+	}
+	if !slices.Contains(options, "omitempty") {
+		return false
+	}
+
+	switch v.Kind() { //nolint:exhaustive // Other kinds use their zero value.
+	case reflect.Slice, reflect.Map, reflect.String, reflect.Array:
+		return v.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	default:
+		if t, ok := v.Interface().(time.Time); ok {
+			return t.IsZero()
+		}
+		return v.IsZero()
+	}
+}
@@ -0,0 +1,140 @@
+package tree_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/mdfm/internal/tree"
+)
+
+type encodeBase struct {
+	ID int `json:"id" yaml:"id"`
+}
+
+type encodeTarget struct {
+	encodeBase `yaml:",inline"`
+
+	Title   string            `json:"title"             yaml:"title"`
+	Draft   bool              `json:"draft,omitempty"   yaml:"draft,omitempty"`
+	Date    time.Time         `json:"date"              yaml:"date"`
+	Labels  map[string]string `json:"labels,omitempty"  yaml:"labels,omitempty"`
+	Authors []string          `json:"authors"           yaml:"authors"`
+	Skipped string            `json:"-"                 yaml:"-"`
+	Rating  float32
+}
+
+func TestEncode(t *testing.T) {
+	date := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	v := encodeTarget{
+		encodeBase: encodeBase{ID: 7},
+		Title:      "Hello",
+		Date:       date,
+		Labels:     map[string]string{"b": "2", "a": "1"},
+		Authors:    []string{"alice"},
+		Skipped:    "x",
+		Rating:     4.5,
+	}
+
+	t.Run("yaml tags", func(t *testing.T) {
+		encoded, err := tree.Encode(v, "yaml")
+		require.NoError(t, err)
+
+		om, ok := encoded.(*tree.OrderedMap)
+		require.True(t, ok)
+		assert.Equal(t, []string{"id", "title", "date", "labels", "authors", "rating"}, om.Keys())
+
+		labels, _ := om.Get("labels")
+		assert.Equal(t, []string{"a", "b"}, labels.(*tree.OrderedMap).Keys())
+
+		id, _ := om.Get("id")
+		assert.Equal(t, int64(7), id)
+		d, _ := om.Get("date")
+		assert.Equal(t, date, d)
+		rating, _ := om.Get("rating")
+		assert.InDelta(t, 4.5, rating, 0)
+	})
+
+	t.Run("json tags promote embedded fields", func(t *testing.T) {
+		encoded, err := tree.Encode(&v, "json")
+		require.NoError(t, err)
+
+		om, ok := encoded.(*tree.OrderedMap)
+		require.True(t, ok)
+		assert.Equal(t, []string{"id", "title", "date", "labels", "authors", "Rating"}, om.Keys())
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		encoded, err := tree.Encode((*encodeTarget)(nil), "yaml")
+		require.NoError(t, err)
+		assert.Nil(t, encoded)
+	})
+
+	t.Run("unsupported value", func(t *testing.T) {
+		_, err := tree.Encode(map[string]any{"f": func() {}}, "yaml")
+		require.Error(t, err)
+	})
+}
+
+func TestMerge(t *testing.T) {
+	old := tree.NewOrderedMap()
+	old.Set("title", "Old")
+	old.Set("date", "2024-01-02")
+	old.Set("extra", true)
+	old.Set("removed", 1)
+	old.Set("tags", []any{"a"})
+
+	updated := tree.NewOrderedMap()
+	updated.Set("added", "new")
+	updated.Set("tags", []any{"a", "b"})
+	updated.Set("date", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC))
+	updated.Set("title", "New")
+	updated.Set("empty", "")
+
+	merged, ok := tree.Merge(old, updated, tree.MergeOptions{
+		Keep:      func(path string) bool { return path == "extra" },
+		OmitEmpty: true,
+	}).(*tree.OrderedMap)
+	require.True(t, ok)
+
+	assert.Equal(t, []string{"title", "date", "extra", "tags", "added"}, merged.Keys())
+
+	title, _ := merged.Get("title")
+	assert.Equal(t, "New", title)
+	date, _ := merged.Get("date")
+	assert.Equal(t, "2024-01-02", date, "equal values keep their original form")
+	tags, _ := merged.Get("tags")
+	assert.Equal(t, []any{"a", "b"}, tags)
+
+	merged, ok = tree.Merge(old, updated, tree.MergeOptions{}).(*tree.OrderedMap)
+	require.True(t, ok)
+	assert.Equal(t, []string{"title", "date", "tags", "added", "empty"}, merged.Keys())
+
+	merged, ok = tree.Merge(old, updated, tree.MergeOptions{UpdatedOrder: true}).(*tree.OrderedMap)
+	require.True(t, ok)
+	assert.Equal(t, []string{"added", "tags", "date", "title", "empty"}, merged.Keys())
+}
+
+func TestEqual(t *testing.T) {
+	a := tree.NewOrderedMap()
+	a.Set("x", int64(1))
+	a.Set("y", "z")
+	b := tree.NewOrderedMap()
+	b.Set("y", "z")
+	b.Set("x", float64(1))
+
+	assert.True(t, tree.Equal(a, b))
+	assert.True(t, tree.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), "2024-01-02"))
+
+	// TOML local dates are decoded with the offset of the machine, which must not matter.
+	for _, offset := range []int{-4, 0, 9} {
+		localDate := time.Date(2024, 1, 2, 0, 0, 0, 0, time.FixedZone("date-local", offset*60*60))
+		assert.True(t, tree.Equal(localDate, "2024-01-02"), "offset %d", offset)
+		assert.True(t, tree.Equal(localDate, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)), "offset %d", offset)
+		assert.False(t, tree.Equal(localDate, "2024-01-03"), "offset %d", offset)
+	}
+	assert.False(t, tree.Equal([]any{"a"}, []any{"a", "b"}))
+	assert.False(t, tree.Equal(int64(1), "1"))
+}
@@ -0,0 +1,87 @@
+package tree
+
+import (
+	"math"
+	"time"
+)
+
+// Location names used by github.com/BurntSushi/toml for TOML local dates and times.
+const (
+	tomlLocalDatetime = "datetime-local"
+	tomlLocalDate     = "date-local"
+	tomlLocalTime     = "time-local"
+)
+
+// JSONSafe converts a tree returned by Normalize into values that encoding/json can always encode
+// and that mean the same thing regardless of the source format:
+//
+//   - datetimes become RFC 3339 strings; TOML local dates, times and datetimes keep their
+//     local form, e.g. "2006-01-02", "15:04:05" or "2006-01-02T15:04:05",
+//   - NaN and infinite floats become the strings "NaN", "Infinity" and "-Infinity".
+//
+// Ordered maps are copied with their order preserved. Other values are returned as is.
+func JSONSafe(v any) any {
+	switch v := v.(type) {
+	case OrderedMap:
+		return JSONSafe(&v)
+	case *OrderedMap:
+		om := NewOrderedMap()
+		for key, value := range v.All() {
+			om.Set(key, JSONSafe(value))
+		}
+		return om
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for key, value := range v {
+			m[key] = JSONSafe(value)
+		}
+		return m
+	case []any:
+		s := make([]any, len(v))
+		for i, item := range v {
+			s[i] = JSONSafe(item)
+		}
+		return s
+	case time.Time:
+		return FormatTime(v)
+	case float64:
+		switch {
+		case math.IsNaN(v):
+			return "NaN"
+		case math.IsInf(v, 1):
+			return "Infinity"
+		case math.IsInf(v, -1):
+			return "-Infinity"
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// FormatTime formats t in RFC 3339 format, or in the local form of TOML local dates, times and datetimes.
+// The local form does not depend on the offset the TOML decoder gives them, which is the one of the machine.
+func FormatTime(t time.Time) string {
+	switch t.Location().String() {
+	case tomlLocalDatetime:
+		return t.Format("2006-01-02T15:04:05.999999999")
+	case tomlLocalDate:
+		return t.Format(time.DateOnly)
+	case tomlLocalTime:
+		return t.Format("15:04:05.999999999")
+	default:
+		return t.Format(time.RFC3339Nano)
+	}
+}
+
+// wallClock returns TOML local dates, times and datetimes with their wall clock in UTC, so that they
+// compare like the dates and datetimes without a zone that ParseTime returns, whatever the offset of
+// the machine they were decoded on. Other times are returned as is.
+func wallClock(t time.Time) time.Time {
+	switch t.Location().String() {
+	case tomlLocalDatetime, tomlLocalDate, tomlLocalTime:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	default:
+		return t
+	}
+}
@@ -0,0 +1,177 @@
+package tree
+
+import (
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// MergeOptions configure Merge.
+type MergeOptions struct {
+	// Keep reports whether a key only present in old is kept, e.g. because the decoded type
+	// cannot represent it. Keys are identified by their path. If nil, no key is kept.
+	Keep func(path string) bool
+
+	// OmitEmpty leaves out the keys only present in updated whose value is empty (see IsEmpty),
+	// so that the zero fields of a struct do not all get added.
+	OmitEmpty bool
+
+	// UpdatedOrder orders keys as in updated instead of old, for values whose mappings keep
+	// the order of the document, such as OrderedMap.
+	UpdatedOrder bool
+}
+
+// Merge combines old, the tree decoded from a document, with updated, the tree encoded from
+// the modified value, so that rewriting the document changes as little as possible:
+//
+//   - keys keep their position in old (or in updated with opts.UpdatedOrder),
+//     keys only present in updated are added at the end,
+//   - keys only present in old are removed, unless opts.Keep reports true for their path,
+//   - values that are Equal to their old counterpart are taken from old, so that
+//     e.g. a date written as "2024-01-02" is not rewritten as a datetime.
+//
+// Paths are joined with "." as in "seo.description" or "authors.0.name".
+func Merge(old, updated any, opts MergeOptions) any {
+	return merger(opts).merge("", old, updated)
+}
+
+type merger MergeOptions
+
+func (m merger) merge(path string, old, updated any) any {
+	switch u := updated.(type) {
+	case *OrderedMap:
+		o, ok := old.(*OrderedMap)
+		if !ok {
+			return updated
+		}
+		return m.mergeMaps(path, o, u)
+	case []any:
+		o, ok := old.([]any)
+		if !ok {
+			return updated
+		}
+
+		merged := make([]any, len(u))
+		for i, item := range u {
+			if i < len(o) {
+				merged[i] = m.merge(joinPath(path, strconv.Itoa(i)), o[i], item)
+			} else {
+				merged[i] = item
+			}
+		}
+		return merged
+	default:
+		if Equal(old, updated) {
+			return old
+		}
+		return updated
+	}
+}
+
+func (m merger) mergeMaps(path string, old, updated *OrderedMap) *OrderedMap {
+	merged := NewOrderedMap()
+	add := func(key string, value any) {
+		if _, done := merged.Get(key); done {
+			return
+		}
+		oldValue, found := old.Get(key)
+		switch {
+		case found:
+			merged.Set(key, m.merge(joinPath(path, key), oldValue, value))
+		case !m.OmitEmpty || !IsEmpty(value):
+			merged.Set(key, value)
+		}
+	}
+
+	if m.UpdatedOrder {
+		for key, value := range updated.All() {
+			add(key, value)
+		}
+	}
+	for key, oldValue := range old.All() {
+		if value, found := updated.Get(key); found {
+			add(key, value)
+		} else if m.Keep != nil && m.Keep(joinPath(path, key)) {
+			merged.Set(key, oldValue)
+		}
+	}
+	for key, value := range updated.All() {
+		add(key, value)
+	}
+	return merged
+}
+
+// Equal reports whether the trees a and b hold the same data, regardless of key order.
+// Integers equal floats with the same value, and datetimes equal strings that Decode
+// would parse as the same instant.
+func Equal(a, b any) bool {
+	switch a := a.(type) {
+	case *OrderedMap:
+		b, ok := b.(*OrderedMap)
+		if !ok || a.Len() != b.Len() {
+			return false
+		}
+		for key, value := range a.All() {
+			other, found := b.Get(key)
+			if !found || !Equal(value, other) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		b, ok := b.([]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !Equal(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case time.Time:
+		return equalTime(a, b)
+	case string:
+		if t, ok := b.(time.Time); ok {
+			return equalTime(t, a)
+		}
+	case int64:
+		if f, ok := b.(float64); ok {
+			return float64(a) == f
+		}
+	case float64:
+		if i, ok := b.(int64); ok {
+			return a == float64(i)
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// IsEmpty reports whether v is null, false, zero, an empty string, sequence or mapping,
+// or the zero time.
+func IsEmpty(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case *OrderedMap:
+		return v.Len() == 0
+	case []any:
+		return len(v) == 0
+	case time.Time:
+		return v.IsZero()
+	default:
+		return reflect.ValueOf(v).IsZero()
+	}
+}
+
+func equalTime(t time.Time, other any) bool {
+	switch other := other.(type) {
+	case time.Time:
+		return wallClock(t).Equal(wallClock(other))
+	case string:
+		if parsed, ok := ParseTime(other); ok {
+			return wallClock(t).Equal(parsed)
+		}
+	}
+	return false
+}
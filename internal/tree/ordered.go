@@ -0,0 +1,362 @@
+package tree
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// OrderedMap is a mapping that remembers the order in which its keys were added.
+//
+// Nested mappings are stored as *OrderedMap, sequences as []any and scalars as the values
+// returned by Normalize. The zero value is an empty map ready to use.
+type OrderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+// NewOrderedMap returns an empty OrderedMap.
+func NewOrderedMap() *OrderedMap {
+	return &OrderedMap{}
+}
+
+// Len returns the number of keys in m.
+func (m *OrderedMap) Len() int {
+	return len(m.keys)
+}
+
+// Keys returns the keys of m in order.
+func (m *OrderedMap) Keys() []string {
+	return slices.Clone(m.keys)
+}
+
+// Get returns the value stored for key and whether the key is present.
+func (m *OrderedMap) Get(key string) (any, bool) {
+	v, ok := m.values[key]
+	return v, ok
+}
+
+// Set stores value for key. New keys are added at the end; existing keys keep their position.
+func (m *OrderedMap) Set(key string, value any) {
+	if m.values == nil {
+		m.values = make(map[string]any)
+	}
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// Delete removes key from m.
+func (m *OrderedMap) Delete(key string) {
+	if _, ok := m.values[key]; !ok {
+		return
+	}
+	delete(m.values, key)
+	m.keys = slices.DeleteFunc(m.keys, func(k string) bool { return k == key })
+}
+
+// Rename changes the key from to to, keeping its position and value, and reports whether
+// from was present. An existing value for to is replaced.
+func (m *OrderedMap) Rename(from, to string) bool {
+	value, ok := m.values[from]
+	if !ok {
+		return false
+	}
+	if from == to {
+		return true
+	}
+
+	m.Delete(to)
+	m.keys[slices.Index(m.keys, from)] = to
+	delete(m.values, from)
+	m.values[to] = value
+	return true
+}
+
+// GetPath returns the value stored at path, a sequence of keys joined with "." such as
+// "seo.description", and whether it is present.
+func (m *OrderedMap) GetPath(path string) (any, bool) {
+	parent, key, err := m.parent(path, false)
+	if err != nil || parent == nil {
+		return nil, false
+	}
+	return parent.Get(key)
+}
+
+// SetPath stores value at path, a sequence of keys joined with "." such as "seo.description".
+// Missing intermediate mappings are created; it fails if an intermediate value is not a mapping.
+func (m *OrderedMap) SetPath(path string, value any) error {
+	parent, key, err := m.parent(path, true)
+	if err != nil {
+		return err
+	}
+	parent.Set(key, value)
+	return nil
+}
+
+// DeletePath removes the value stored at path, a sequence of keys joined with "." such as
+// "seo.description", and reports whether it was present.
+func (m *OrderedMap) DeletePath(path string) bool {
+	parent, key, err := m.parent(path, false)
+	if err != nil || parent == nil {
+		return false
+	}
+	if _, ok := parent.Get(key); !ok {
+		return false
+	}
+	parent.Delete(key)
+	return true
+}
+
+// All iterates over the keys and values of m in order.
+func (m *OrderedMap) All() iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		for _, key := range m.keys {
+			if !yield(key, m.values[key]) {
+				return
+			}
+		}
+	}
+}
+
+// String formats m like a map, with its keys in order.
+func (m *OrderedMap) String() string {
+	var sb strings.Builder
+	sb.WriteString("map[")
+	for i, key := range m.keys {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		fmt.Fprintf(&sb, "%s:%v", key, m.values[key])
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
+
+// MarshalJSON encodes m as a JSON object with its keys in order.
+// HTML characters are not escaped here, so that the calling encoder decides.
+func (m OrderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := encodeJSON(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := encodeJSON(m.values[key])
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", key, err)
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON decodes a JSON object into m, keeping its keys in order.
+func (m *OrderedMap) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil
+	}
+	if tok != json.Delim('{') {
+		return fmt.Errorf("cannot unmarshal %v into an ordered map", tok)
+	}
+
+	om, err := readJSONObject(dec)
+	if err != nil {
+		return err
+	}
+	*m = *om
+	return nil
+}
+
+// UnmarshalYAML decodes a YAML mapping into m, keeping its keys in order.
+// It implements the yaml.v2 Unmarshaler interface.
+func (m *OrderedMap) UnmarshalYAML(unmarshal func(any) error) error {
+	var ms yaml.MapSlice
+	if err := unmarshal(&ms); err != nil {
+		return err
+	}
+	*m = *fromMapSlice(ms)
+	return nil
+}
+
+// UnmarshalTOML decodes a TOML table into m.
+// The TOML decoder does not report the order of keys to Unmarshaler implementations,
+// so keys are sorted alphabetically.
+func (m *OrderedMap) UnmarshalTOML(v any) error {
+	om, ok := Ordered(Normalize(v), nil).(*OrderedMap)
+	if !ok {
+		return fmt.Errorf("cannot unmarshal %T into an ordered map", v)
+	}
+	*m = *om
+	return nil
+}
+
+// parent returns the mapping holding the last key of path, along with that key.
+// With create, missing mappings are added; otherwise nil is returned if one is missing.
+func (m *OrderedMap) parent(path string, create bool) (*OrderedMap, string, error) {
+	keys := strings.Split(path, ".")
+	current := m
+	for i, key := range keys[:len(keys)-1] {
+		value, ok := current.Get(key)
+		if !ok {
+			if !create {
+				return nil, "", nil
+			}
+			value = NewOrderedMap()
+			current.Set(key, value)
+		}
+
+		next, isMap := value.(*OrderedMap)
+		if !isMap {
+			return nil, "", fmt.Errorf("%s is not a mapping", strings.Join(keys[:i+1], "."))
+		}
+		current = next
+	}
+	return current, keys[len(keys)-1], nil
+}
+
+// Ordered converts mappings of a tree returned by Normalize into *OrderedMap.
+//
+// The keys of each mapping are sorted by compare, which receives the path of the mapping
+// (without sequence indexes) and two keys. If compare is nil, keys are sorted alphabetically.
+func Ordered(v any, compare func(path []string, a, b string) int) any {
+	return ordered(nil, v, compare)
+}
+
+func ordered(path []string, v any, compare func(path []string, a, b string) int) any {
+	switch v := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		if compare == nil {
+			slices.Sort(keys)
+		} else {
+			slices.SortFunc(keys, func(a, b string) int { return compare(path, a, b) })
+		}
+
+		om := NewOrderedMap()
+		for _, key := range keys {
+			om.Set(key, ordered(append(slices.Clip(path), key), v[key], compare))
+		}
+		return om
+	case []any:
+		s := make([]any, len(v))
+		for i, item := range v {
+			s[i] = ordered(path, item, compare)
+		}
+		return s
+	default:
+		return v
+	}
+}
+
+func encodeJSON(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func fromMapSlice(ms yaml.MapSlice) *OrderedMap {
+	om := NewOrderedMap()
+	for _, item := range ms {
+		om.Set(fmt.Sprint(item.Key), fromYAML(item.Value))
+	}
+	return om
+}
+
+func fromYAML(v any) any {
+	switch v := v.(type) {
+	case yaml.MapSlice:
+		return fromMapSlice(v)
+	case []any:
+		s := make([]any, len(v))
+		for i, item := range v {
+			s[i] = fromYAML(item)
+		}
+		return s
+	default:
+		return Normalize(v)
+	}
+}
+
+func readJSONValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		return readJSONObject(dec)
+	case json.Delim('['):
+		var s []any
+		for dec.More() {
+			item, iErr := readJSONValue(dec)
+			if iErr != nil {
+				return nil, iErr
+			}
+			s = append(s, item)
+		}
+		if _, cErr := dec.Token(); cErr != nil {
+			return nil, cErr
+		}
+		if s == nil {
+			s = []any{}
+		}
+		return s, nil
+	default:
+		return Normalize(tok), nil
+	}
+}
+
+// readJSONObject reads the members of an object whose opening brace has already been read.
+func readJSONObject(dec *json.Decoder) (*OrderedMap, error) {
+	om := NewOrderedMap()
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return nil, errors.New("object key is not a string")
+		}
+
+		value, vErr := readJSONValue(dec)
+		if vErr != nil {
+			return nil, vErr
+		}
+		om.Set(key, value)
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return om, nil
+}
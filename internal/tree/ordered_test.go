@@ -0,0 +1,131 @@
+package tree_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/sushichan044/mdfm/internal/tree"
+)
+
+func TestOrderedMap(t *testing.T) {
+	var om tree.OrderedMap
+	om.Set("b", 1)
+	om.Set("a", 2)
+	om.Set("c", 3)
+	om.Set("b", 4)
+	om.Delete("a")
+	om.Delete("missing")
+
+	assert.Equal(t, 2, om.Len())
+	assert.Equal(t, []string{"b", "c"}, om.Keys())
+
+	v, ok := om.Get("b")
+	assert.True(t, ok)
+	assert.Equal(t, 4, v)
+	_, ok = om.Get("a")
+	assert.False(t, ok)
+
+	var keys []string
+	for key := range om.All() {
+		keys = append(keys, key)
+		break
+	}
+	assert.Equal(t, []string{"b"}, keys)
+	assert.Equal(t, "map[b:4 c:3]", om.String())
+}
+
+func TestOrderedMap_Paths(t *testing.T) {
+	om := tree.NewOrderedMap()
+	require.NoError(t, om.SetPath("seo.title", "Hello"))
+	require.NoError(t, om.SetPath("seo.description", "World"))
+	require.NoError(t, om.SetPath("draft", false))
+
+	assert.Equal(t, []string{"seo", "draft"}, om.Keys())
+	v, ok := om.GetPath("seo.description")
+	assert.True(t, ok)
+	assert.Equal(t, "World", v)
+	_, ok = om.GetPath("seo.missing.key")
+	assert.False(t, ok)
+
+	require.EqualError(t, om.SetPath("draft.value", true), "draft is not a mapping")
+
+	require.NoError(t, om.SetPath("slug", "hello"))
+	assert.True(t, om.Rename("seo", "meta"))
+	assert.True(t, om.Rename("slug", "draft"))
+	assert.False(t, om.Rename("missing", "x"))
+	assert.Equal(t, []string{"meta", "draft"}, om.Keys())
+	om.Rename("meta", "seo")
+	om.Set("draft", false)
+
+	assert.True(t, om.DeletePath("seo.title"))
+	assert.False(t, om.DeletePath("seo.title"))
+	assert.False(t, om.DeletePath("missing.key"))
+	assert.Equal(t, "map[seo:map[description:World] draft:false]", om.String())
+}
+
+func TestOrderedMap_JSON(t *testing.T) {
+	input := `{"z": 1, "a": {"y": [1.5, {"x": null}], "b": "<tag>"}, "m": []}`
+
+	var om tree.OrderedMap
+	require.NoError(t, json.Unmarshal([]byte(input), &om))
+	assert.Equal(t, []string{"z", "a", "m"}, om.Keys())
+
+	z, _ := om.Get("z")
+	assert.Equal(t, int64(1), z)
+
+	out, err := json.Marshal(om)
+	require.NoError(t, err)
+	assert.Equal(t, `{"z":1,"a":{"y":[1.5,{"x":null}],"b":"\u003ctag\u003e"},"m":[]}`, string(out))
+
+	var buf strings.Builder
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	require.NoError(t, enc.Encode(om))
+	assert.Equal(t, `{"z":1,"a":{"y":[1.5,{"x":null}],"b":"<tag>"},"m":[]}`+"\n", buf.String())
+
+	require.Error(t, json.Unmarshal([]byte(`[1]`), &om))
+}
+
+func TestOrderedMap_YAML(t *testing.T) {
+	input := "z: 1\na:\n  w: [1.5, {x: ~}]\n  2: two\n"
+
+	var om tree.OrderedMap
+	require.NoError(t, yaml.Unmarshal([]byte(input), &om))
+	assert.Equal(t, []string{"z", "a"}, om.Keys())
+
+	a, _ := om.Get("a")
+	require.IsType(t, &tree.OrderedMap{}, a)
+	assert.Equal(t, []string{"w", "2"}, a.(*tree.OrderedMap).Keys())
+
+	out, err := json.Marshal(&om)
+	require.NoError(t, err)
+	assert.Equal(t, `{"z":1,"a":{"w":[1.5,{"x":null}],"2":"two"}}`, string(out))
+}
+
+func TestOrdered(t *testing.T) {
+	src := map[string]any{
+		"b": int64(1),
+		"a": []any{map[string]any{"d": int64(2), "c": int64(3)}},
+	}
+
+	sorted, ok := tree.Ordered(src, nil).(*tree.OrderedMap)
+	require.True(t, ok)
+	assert.Equal(t, []string{"a", "b"}, sorted.Keys())
+
+	var paths [][]string
+	reversed, ok := tree.Ordered(src, func(path []string, a, b string) int {
+		paths = append(paths, path)
+		return strings.Compare(b, a)
+	}).(*tree.OrderedMap)
+	require.True(t, ok)
+	assert.Equal(t, []string{"b", "a"}, reversed.Keys())
+
+	list, _ := reversed.Get("a")
+	assert.Equal(t, []string{"d", "c"}, list.([]any)[0].(*tree.OrderedMap).Keys())
+	assert.Contains(t, paths, []string{"a"})
+}
@@ -0,0 +1,60 @@
+// Package tree converts decoded frontmatter into a format-independent tree of plain Go values
+// and decodes such trees into arbitrary Go types.
+package tree
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// Normalize converts a value produced by a YAML, TOML or JSON decoder into a tree made of
+//
+//   - map[string]any for mappings and tables; non-string keys are formatted with fmt.Sprint,
+//   - []any for sequences and arrays,
+//   - int64 for integers, float64 for other numbers,
+//   - string, bool, time.Time and nil.
+//
+// Integers that do not fit into an int64 are converted to float64.
+// Values of any other type are returned as is.
+func Normalize(v any) any {
+	switch v := v.(type) {
+	case nil, string, bool, int64, float64, time.Time:
+		return v
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case float32:
+		return float64(v)
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() { //nolint:exhaustive // Other kinds are returned as is.
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u := rv.Uint(); u <= math.MaxInt64 {
+			return int64(u)
+		}
+		return float64(rv.Uint())
+	case reflect.Map:
+		m := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			m[fmt.Sprint(iter.Key().Interface())] = Normalize(iter.Value().Interface())
+		}
+		return m
+	case reflect.Slice, reflect.Array:
+		s := make([]any, rv.Len())
+		for i := range rv.Len() {
+			s[i] = Normalize(rv.Index(i).Interface())
+		}
+		return s
+	}
+	return v
+}
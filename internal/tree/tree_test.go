@@ -0,0 +1,238 @@
+package tree_test
+
+import (
+	"encoding/json"
+	"math"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/mdfm/internal/tree"
+)
+
+func TestNormalize(t *testing.T) {
+	date := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	input := map[any]any{
+		"int":    1,
+		"uint":   uint64(math.MaxUint64),
+		"float":  float32(1.5),
+		"number": json.Number("42"),
+		"real":   json.Number("4.2"),
+		"date":   date,
+		"nested": map[any]any{1: "one"},
+		"list":   []any{int8(1), "two"},
+		"tables": []map[string]any{{"name": "a"}},
+		"null":   nil,
+	}
+
+	assert.Equal(t, map[string]any{
+		"int":    int64(1),
+		"uint":   float64(math.MaxUint64),
+		"float":  1.5,
+		"number": int64(42),
+		"real":   4.2,
+		"date":   date,
+		"nested": map[string]any{"1": "one"},
+		"list":   []any{int64(1), "two"},
+		"tables": []any{map[string]any{"name": "a"}},
+		"null":   nil,
+	}, tree.Normalize(input))
+}
+
+func TestJSONSafe(t *testing.T) {
+	date := time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("", 9*60*60))
+
+	input := map[string]any{
+		"date": date,
+		"nan":  math.NaN(),
+		"list": []any{math.Inf(1), math.Inf(-1), 1.5, int64(1)},
+	}
+
+	assert.Equal(t, map[string]any{
+		"date": "2024-01-02T03:04:05+09:00",
+		"nan":  "NaN",
+		"list": []any{"Infinity", "-Infinity", 1.5, int64(1)},
+	}, tree.JSONSafe(input))
+}
+
+func TestJSONSafe_TOMLLocalTimes(t *testing.T) {
+	var v map[string]any
+	_, err := toml.Decode(`
+offset = 2024-01-02T03:04:05+09:00
+datetime = 2024-01-02T03:04:05
+date = 2024-01-02
+time = 03:04:05.5
+`, &v)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{
+		"offset":   "2024-01-02T03:04:05+09:00",
+		"datetime": "2024-01-02T03:04:05",
+		"date":     "2024-01-02",
+		"time":     "03:04:05.5",
+	}, tree.JSONSafe(tree.Normalize(v)))
+}
+
+type embedded struct {
+	Draft bool `mdfm:"draft"`
+}
+
+type author struct {
+	Name string `mdfm:"name"`
+}
+
+type document struct {
+	embedded
+
+	Title   string            `mdfm:"title"`
+	Count   uint8             `mdfm:"count"`
+	Ratio   float32           `mdfm:"ratio"`
+	Date    time.Time         `mdfm:"date"`
+	Day     time.Time         `mdfm:"day"`
+	Updated string            `mdfm:"updated"`
+	Addr    netip.Addr        `mdfm:"addr"`
+	Authors []author          `mdfm:"authors"`
+	Pair    [2]int            `mdfm:"pair"`
+	Labels  map[string]string `mdfm:"labels"`
+	Extra   any               `mdfm:"extra"`
+	Cover   *author           `mdfm:"cover"`
+	Skipped string            `mdfm:"-"`
+	Summary string
+}
+
+func TestDecode(t *testing.T) {
+	date := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	src := map[string]any{
+		"title":   "Hello",
+		"draft":   true,
+		"count":   float64(3),
+		"ratio":   int64(2),
+		"date":    "2024-01-02T03:04:05Z",
+		"day":     "2024-01-02",
+		"updated": date,
+		"addr":    "127.0.0.1",
+		"authors": []any{map[string]any{"NAME": "Alice"}},
+		"pair":    []any{int64(1), int64(2), int64(3)},
+		"labels":  map[string]any{"a": "b"},
+		"extra":   map[string]any{"k": []any{int64(1)}},
+		"cover":   map[string]any{"name": "Bob"},
+		"skipped": "ignored",
+		"summary": "matched by field name",
+		"unknown": "ignored",
+	}
+
+	var doc document
+	require.NoError(t, tree.Decode(src, &doc, "mdfm"))
+
+	assert.Equal(t, document{
+		embedded: embedded{Draft: true},
+		Title:    "Hello",
+		Count:    3,
+		Ratio:    2,
+		Date:     date,
+		Day:      time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		Updated:  "2024-01-02T03:04:05Z",
+		Addr:     netip.MustParseAddr("127.0.0.1"),
+		Authors:  []author{{Name: "Alice"}},
+		Pair:     [2]int{1, 2},
+		Labels:   map[string]string{"a": "b"},
+		Extra:    map[string]any{"k": []any{int64(1)}},
+		Cover:    &author{Name: "Bob"},
+		Summary:  "matched by field name",
+	}, doc)
+}
+
+func TestDecode_Scalars(t *testing.T) {
+	type scalars struct {
+		Year    string    `mdfm:"year"`
+		Version string    `mdfm:"version"`
+		Ratio   string    `mdfm:"ratio"`
+		Flag    string    `mdfm:"flag"`
+		Day     time.Time `mdfm:"day"`
+		At      time.Time `mdfm:"at"`
+	}
+
+	offset := time.FixedZone("date-local", 9*60*60)
+	src := map[string]any{
+		"year":    int64(2024),
+		"version": 1.0,
+		"ratio":   0.25,
+		"flag":    true,
+		"day":     time.Date(2024, 1, 2, 0, 0, 0, 0, offset),
+		"at":      time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("datetime-local", -5*60*60)),
+	}
+
+	var doc scalars
+	require.NoError(t, tree.Decode(src, &doc, "mdfm"))
+
+	assert.Equal(t, scalars{
+		Year:    "2024",
+		Version: "1.0",
+		Ratio:   "0.25",
+		Flag:    "true",
+		Day:     time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		At:      time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}, doc)
+}
+
+func TestDecode_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      map[string]any
+		path     string
+		contains string
+	}{
+		{
+			name:     "type mismatch",
+			src:      map[string]any{"title": []any{"a"}},
+			path:     "title",
+			contains: "cannot decode list into string",
+		},
+		{
+			name:     "fractional number into integer",
+			src:      map[string]any{"count": 1.5},
+			path:     "count",
+			contains: "cannot decode number 1.5 into uint8",
+		},
+		{
+			name:     "overflow",
+			src:      map[string]any{"count": int64(256)},
+			path:     "count",
+			contains: "integer 256 overflows uint8",
+		},
+		{
+			name:     "invalid date",
+			src:      map[string]any{"date": "yesterday"},
+			path:     "date",
+			contains: `cannot parse "yesterday" as a date`,
+		},
+		{
+			name:     "nested",
+			src:      map[string]any{"authors": []any{map[string]any{"name": map[string]any{}}}},
+			path:     "authors.0.name",
+			contains: "cannot decode mapping into string",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc document
+			err := tree.Decode(tt.src, &doc, "mdfm")
+
+			var fieldErr *tree.FieldError
+			require.ErrorAs(t, err, &fieldErr)
+			assert.Equal(t, tt.path, fieldErr.Path)
+			assert.Contains(t, fieldErr.Error(), tt.contains)
+		})
+	}
+
+	t.Run("non-pointer output", func(t *testing.T) {
+		require.Error(t, tree.Decode(map[string]any{}, document{}, "mdfm"))
+	})
+}
@@ -0,0 +1,86 @@
+package mdfm
+
+import (
+	"context"
+	"errors"
+	"iter"
+
+	"github.com/sushichan044/mdfm/internal/concurrent"
+)
+
+// errStopWalk stops discovery once the caller of All no longer wants results.
+var errStopWalk = errors.New("stop walking")
+
+// All finds Markdown files matching the given glob pattern and returns an iterator over
+// their parsed results, for use with range-over-func:
+//
+//	for result, err := range mdfm.All[Article]("content/**/*.md") {
+//		if err != nil {
+//			log.Printf("skipping %s: %v", result.Path, err)
+//			continue
+//		}
+//		if result.Document.FrontMatter.Slug == "hello-world" {
+//			fmt.Println("found", result.Path)
+//			break
+//		}
+//	}
+//
+// Files are discovered lazily as the loop advances, then read and parsed concurrently, and results
+// are yielded in completion order. At most WithConcurrency files are read ahead of the loop body, so
+// breaking out of the loop stops both discovery and reading: early-exit searches do not leak
+// goroutines or read more files than necessary.
+//
+// The error is non-nil in two cases:
+//   - a single file could not be read or parsed: err equals result.Err and iteration continues
+//     with the other files.
+//   - the files could not be discovered, e.g. because the pattern is invalid: result is the zero
+//     value and it is the last value yielded.
+func All[T any](glob string, opts ...Option) iter.Seq2[Result[T], error] {
+	return AllContext[T](context.Background(), glob, opts...)
+}
+
+// AllContext is like All but accepts a context for cancellation.
+// Once ctx is done, files that have not been read yet are yielded with an error wrapping ctx.Err(),
+// and discovery stops by yielding ctx.Err() with the zero value, like an invalid pattern.
+func AllContext[T any](ctx context.Context, glob string, opts ...Option) iter.Seq2[Result[T], error] {
+	return func(yield func(Result[T], error) bool) {
+		o, err := newOptions(opts...)
+		if err != nil {
+			yield(Result[T]{}, err)
+			return
+		}
+
+		src, err := newSource(o)
+		if err != nil {
+			yield(Result[T]{}, err)
+			return
+		}
+
+		var walkErr error
+		tasks := func(yieldTask func(concurrent.Task[*MarkdownDocument[T], string]) bool) {
+			err := src.each(ctx, append([]string{glob}, o.patterns...), func(p string) error {
+				if !yieldTask(newMarkdownTask[T](src, o, p)) {
+					return errStopWalk
+				}
+				return nil
+			})
+			if err != nil && !errors.Is(err, errStopWalk) {
+				walkErr = err
+			}
+		}
+
+		executions := concurrent.RunSeqContext(ctx, tasks, concurrent.WithMaxConcurrency(o.concurrency))
+		for execution := range executions {
+			if errors.Is(execution.Result.Err, errFilteredOut) {
+				continue
+			}
+			if result := newResult(execution); !yield(result, result.Err) {
+				return
+			}
+		}
+
+		if walkErr != nil {
+			yield(Result[T]{}, walkErr)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package mdfm_test
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/mdfm"
+)
+
+// blockingFS counts how many Markdown files have been opened, and blocks every Open of a
+// Markdown file until a token is sent on release.
+type blockingFS struct {
+	fstest.MapFS
+
+	opened  atomic.Int32
+	release chan struct{}
+}
+
+func (b *blockingFS) Open(name string) (fs.File, error) {
+	if path.Ext(name) == ".md" {
+		b.opened.Add(1)
+		<-b.release
+	}
+	return b.MapFS.Open(name)
+}
+
+func TestAll(t *testing.T) {
+	setupTestFiles(t)
+
+	var paths []string
+	var errorCount int
+	for result, err := range mdfm.All[testMetadata]("**/*.md") {
+		if err != nil {
+			require.Equal(t, result.Err, err)
+			errorCount++
+			continue
+		}
+		paths = append(paths, result.Path)
+	}
+
+	assert.Len(t, paths, 6)
+	assert.Equal(t, 1, errorCount)
+}
+
+func TestAll_InvalidPattern(t *testing.T) {
+	setupTestFiles(t)
+
+	var calls int
+	for result, err := range mdfm.All[testMetadata]("[invalid") {
+		calls++
+		require.Error(t, err)
+		assert.Empty(t, result.Path)
+	}
+	assert.Equal(t, 1, calls)
+}
+
+func TestAllContext_Canceled(t *testing.T) {
+	setupTestFiles(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var calls int
+	for result, err := range mdfm.AllContext[testMetadata](ctx, "**/*.md") {
+		calls++
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Empty(t, result.Path)
+	}
+	assert.Equal(t, 1, calls)
+}
+
+func TestAll_BreakStopsReading(t *testing.T) {
+	const concurrency = 2
+
+	mapFS := fstest.MapFS{}
+	for i := range 50 {
+		mapFS[fmt.Sprintf("docs/%02d.md", i)] = &fstest.MapFile{Data: []byte("---\ntitle: doc\n---\n")}
+	}
+	fsys := &blockingFS{MapFS: mapFS, release: make(chan struct{}, 1)}
+	// Let a single read finish; the others block until the test ends.
+	fsys.release <- struct{}{}
+	t.Cleanup(func() { close(fsys.release) })
+
+	var seen int
+	for _, err := range mdfm.All[testMetadata](
+		"docs/*.md",
+		mdfm.WithFS(fsys),
+		mdfm.WithConcurrency(concurrency),
+	) {
+		require.NoError(t, err)
+		seen++
+		// The reads running ahead of the loop body fill up the concurrency slots, and no more.
+		require.Eventually(t, func() bool {
+			return fsys.opened.Load() == concurrency
+		}, time.Second, time.Millisecond)
+		break
+	}
+
+	assert.Equal(t, 1, seen)
+	assert.Equal(t, int32(concurrency), fsys.opened.Load(), "breaking out of the loop should stop pending reads")
+}
@@ -1,7 +1,7 @@
 // Package mdfm provides functionality for finding Markdown files using glob patterns
 // and extracting their frontmatter metadata while respecting Git ignore rules.
 //
-// The main function GlobFrontMatter allows you to search for Markdown files and
+// The main function Glob allows you to search for Markdown files and
 // parse their YAML/TOML frontmatter in a concurrent, type-safe manner.
 //
 // Example usage:
@@ -12,32 +12,31 @@
 //		Published bool     `yaml:"published"`
 //	}
 //
-//	tasks, err := mdfm.GlobFrontMatter[BlogPost]("content/**/*.md")
+//	results, err := mdfm.Glob[BlogPost]("content/**/*.md")
 //	if err != nil {
 //		log.Fatal(err)
 //	}
 //
-//	for _, task := range tasks {
-//		if task.Result.Err != nil {
-//			fmt.Fprintf(os.Stderr, "error processing %s: %s", task.Metadata.Path, task.Result.Err)
+//	for _, result := range results {
+//		if result.Err != nil {
+//			fmt.Fprintf(os.Stderr, "error processing %s: %s", result.Path, result.Err)
 //			continue
 //		}
 //
-//		post := task.Result.Value
+//		post := result.Document
 //		fmt.Printf("Title: %s\n", post.FrontMatter.Title)
 //		fmt.Printf("Content: %s\n", post.BodyString())
 //	}
 package mdfm
 
 import (
-	"bytes"
-	"os"
+	"context"
+	"errors"
+	"io/fs"
 
 	"github.com/basemachina/lo"
-	"github.com/bmatcuk/doublestar/v4"
 
 	"github.com/sushichan044/mdfm/internal/concurrent"
-	"github.com/sushichan044/mdfm/internal/gitignore"
 	"github.com/sushichan044/mdfm/internal/markdown"
 )
 
@@ -57,22 +56,67 @@ type (
 	//	fmt.Println(doc.Body)              // Access markdown content
 	MarkdownDocument[T any] struct {
 		// FrontMatter contains the parsed metadata from the document's frontmatter.
-		// The structure depends on the type parameter T provided to GlobFrontMatter.
+		// The structure depends on the type parameter T provided to Glob.
 		FrontMatter T
 
 		// Body contains the raw markdown content without the frontmatter.
 		// This includes all content after the frontmatter delimiter.
+		// It is nil when the document is parsed with WithoutBody.
 		Body []byte
+
+		// Format is the name of the detected frontmatter format: FormatYAML, FormatTOML or FormatJSON.
+		// It is empty if the document has no frontmatter.
+		Format string
+
+		// RawFrontMatter is the original frontmatter text between the delimiters, exactly as
+		// it appears in the document. For JSON objects that are not enclosed in delimiter lines,
+		// it includes the braces. It is nil if the document has no frontmatter.
+		RawFrontMatter []byte
+
+		// BodyStartLine is the 1-based line number where Body starts in the document.
+		// It is 1 if the document has no frontmatter.
+		BodyStartLine int
+
+		// Positions maps the path of each frontmatter key to where it is defined in the document.
+		// Paths join nested keys with "." and use 0-based indexes for sequence items,
+		// e.g. "seo.description" or "authors.0.name". For TOML, the items of arrays and the keys
+		// of inline tables are not recorded.
+		// It is only populated when the document is parsed with WithPositions.
+		Positions map[string]Position
+
+		// origin describes how the document was parsed, so that Marshal can re-encode
+		// the frontmatter in place.
+		origin origin
 	}
 
-	// MarkdownDocumentMetadata contains metadata about the processing of a Markdown file.
-	// This is separate from the frontmatter content and provides information about
-	// the file itself during processing.
-	MarkdownDocumentMetadata struct {
-		// Path is the file system path to the markdown file, relative to the
-		// current working directory when GlobFrontMatter was called.
-		Path string
+	// origin records the parts of a parsed document that are not exposed by MarkdownDocument.
+	// It only holds plain data so that documents parsed from the same input compare equal.
+	origin struct {
+		// format is the name of the format of the frontmatter block, or empty if there is none.
+		format string
+
+		// fieldTag is the struct tag FrontMatter was decoded with.
+		fieldTag string
+
+		// head and tail are the lines around RawFrontMatter, see markdown.Block.
+		head, tail []byte
 	}
+
+	// Position is a location in a Markdown document.
+	Position struct {
+		// Line is the 1-based line number.
+		Line int `json:"line"`
+
+		// Column is the 1-based column number, counted in bytes.
+		Column int `json:"column"`
+	}
+)
+
+// Names of the frontmatter formats reported by MarkdownDocument.Format and ParseError.Format.
+const (
+	FormatYAML = markdown.FormatYAML
+	FormatTOML = markdown.FormatTOML
+	FormatJSON = markdown.FormatJSON
 )
 
 // BodyString returns the markdown body as a string.
@@ -80,10 +124,6 @@ func (md *MarkdownDocument[T]) BodyString() string {
 	return string(md.Body)
 }
 
-const (
-	readConcurrency = 10
-)
-
 // Glob finds Markdown files matching the given glob pattern and
 // extracts their frontmatter metadata concurrently. It respects Git ignore rules
 // and returns results for successful and failed file processing.
@@ -97,13 +137,15 @@ const (
 //   - "**/*.md" matches all .md files recursively
 //   - "content/{blog,docs}/*.md" matches .md files in blog or docs subdirectories
 //
+// More patterns, including "!"-prefixed exclusions, can be given with WithPatterns.
+//
 // Git integration:
 // Files matching patterns in .gitignore, global Git excludes, or local Git excludes
 // are automatically filtered out from the results.
 //
 // Error handling:
 // The function returns an error only for fatal conditions (e.g., invalid glob pattern).
-// Per-file errors (e.g., invalid frontmatter) are included in individual TaskResult.Err
+// Per-file errors (e.g., invalid frontmatter) are included in individual Result.Err
 // fields, allowing you to handle them on a case-by-case basis.
 //
 // Example usage:
@@ -116,19 +158,19 @@ const (
 //		Published bool      `yaml:"published"`
 //	}
 //
-//	tasks, err := Glob[Article]("content/**/*.md")
+//	results, err := Glob[Article]("content/**/*.md")
 //	if err != nil {
 //		log.Fatalf("Failed to glob files: %v", err)
 //	}
 //
-//	for _, task := range tasks {
-//		if task.Result.Err != nil {
+//	for _, result := range results {
+//		if result.Err != nil {
 //			fmt.Printf("Error processing %s: %v\n",
-//				task.Metadata.Path, task.Result.Err)
+//				result.Path, result.Err)
 //			continue
 //		}
 //
-//		article := task.Result.Value
+//		article := result.Document
 //		if article.FrontMatter.Published {
 //			fmt.Printf("Published: %s by %s\n",
 //				article.FrontMatter.Title, article.FrontMatter.Author)
@@ -137,26 +179,53 @@ const (
 //
 // For dynamic frontmatter (when structure is unknown):
 //
-//	tasks, err := Glob[map[string]any]("**/*.md")
+//	results, err := Glob[map[string]any]("**/*.md")
 //	// ... handle results with type assertions
 func Glob[T any](
 	glob string,
-) ([]concurrent.TaskExecution[*MarkdownDocument[T], MarkdownDocumentMetadata], error) {
-	matched, err := runGlob(glob)
+	opts ...Option,
+) ([]Result[T], error) {
+	return GlobContext[T](context.Background(), glob, opts...)
+}
+
+// GlobContext is like Glob but accepts a context for cancellation.
+//
+// If ctx is done while files are being discovered, the walk stops and ctx.Err() is returned.
+// Once files have been discovered, files that have not been read yet are skipped and reported
+// with a Result.Err wrapping ctx.Err(), so errors.Is(err, context.Canceled)
+// identifies them. Files that are already being read are allowed to finish.
+func GlobContext[T any](
+	ctx context.Context,
+	glob string,
+	opts ...Option,
+) ([]Result[T], error) {
+	o, err := newOptions(opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	tasks := lo.Map(matched, func(path string) concurrent.Task[*MarkdownDocument[T], MarkdownDocumentMetadata] {
-		return concurrent.Task[*MarkdownDocument[T], MarkdownDocumentMetadata]{
-			Metadata: MarkdownDocumentMetadata{Path: path},
-			Run: func() (*MarkdownDocument[T], error) {
-				return processMarkdownFile[T](path)
-			},
-		}
-	})
+	src, err := newSource(o)
+	if err != nil {
+		return nil, err
+	}
 
-	return concurrent.RunAll(tasks, concurrent.WithMaxConcurrency(readConcurrency)), nil
+	matched, err := src.glob(ctx, append([]string{glob}, o.patterns...))
+	if err != nil {
+		return nil, err
+	}
+
+	executions := concurrent.RunAllContext(
+		ctx,
+		newMarkdownTasks[T](src, o, matched),
+		concurrent.WithMaxConcurrency(o.concurrency),
+	)
+	results := make([]Result[T], 0, len(executions))
+	for _, execution := range executions {
+		if !errors.Is(execution.Result.Err, errFilteredOut) {
+			results = append(results, newResult(execution))
+		}
+	}
+	return results, nil
 }
 
 // GlobStream finds Markdown files matching the given glob pattern and
@@ -175,13 +244,15 @@ func Glob[T any](
 //   - "**/*.md" matches all .md files recursively
 //   - "content/{blog,docs}/*.md" matches .md files in blog or docs subdirectories
 //
+// More patterns, including "!"-prefixed exclusions, can be given with WithPatterns.
+//
 // Git integration:
 // Files matching patterns in .gitignore, global Git excludes, or local Git excludes
 // are automatically filtered out from the results.
 //
 // Error handling:
 // The function returns an error only for fatal conditions (e.g., invalid glob pattern).
-// Per-file errors (e.g., invalid frontmatter) are included in individual TaskResult.Err
+// Per-file errors (e.g., invalid frontmatter) are included in individual Result.Err
 // fields in the streamed results, allowing you to handle them on a case-by-case basis.
 //
 // Channel behavior:
@@ -204,14 +275,14 @@ func Glob[T any](
 //		log.Fatalf("Failed to glob files: %v", err)
 //	}
 //
-//	for task := range resultChan {
-//		if task.Result.Err != nil {
+//	for result := range resultChan {
+//		if result.Err != nil {
 //			fmt.Printf("Error processing %s: %v\n",
-//				task.Metadata.Path, task.Result.Err)
+//				result.Path, result.Err)
 //			continue
 //		}
 //
-//		article := task.Result.Value
+//		article := result.Document
 //		if article.FrontMatter.Published {
 //			fmt.Printf("Published: %s by %s\n",
 //				article.FrontMatter.Title, article.FrontMatter.Author)
@@ -224,69 +295,116 @@ func Glob[T any](
 //	// ... consume channel with type assertions
 func GlobStream[T any](
 	glob string,
-) (<-chan concurrent.TaskExecution[*MarkdownDocument[T], MarkdownDocumentMetadata], error) {
-	matched, err := runGlob(glob)
+	opts ...Option,
+) (<-chan Result[T], error) {
+	return GlobStreamContext[T](context.Background(), glob, opts...)
+}
+
+// GlobStreamContext is like GlobStream but accepts a context for cancellation.
+//
+// If ctx is done while files are being discovered, the walk stops and ctx.Err() is returned.
+// Once files have been discovered, files that have not been read yet are not read anymore. They are
+// still sent on the channel with a Result.Err wrapping ctx.Err(), and the channel
+// is closed as soon as the files that were already being read are finished.
+func GlobStreamContext[T any](
+	ctx context.Context,
+	glob string,
+	opts ...Option,
+) (<-chan Result[T], error) {
+	o, err := newOptions(opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	tasks := lo.Map(matched, func(path string) concurrent.Task[*MarkdownDocument[T], MarkdownDocumentMetadata] {
-		return concurrent.Task[*MarkdownDocument[T], MarkdownDocumentMetadata]{
-			Metadata: MarkdownDocumentMetadata{Path: path},
-			Run: func() (*MarkdownDocument[T], error) {
-				return processMarkdownFile[T](path)
-			},
-		}
-	})
-
-	return concurrent.RunAllStream(tasks, concurrent.WithMaxConcurrency(readConcurrency)), nil
-}
-
-// processMarkdownFile reads and parses a single Markdown file.
-// It extracts frontmatter metadata and returns the processed document.
-// This function is used internally by GlobFrontMatter for concurrent processing.
-func processMarkdownFile[T any](path string) (*MarkdownDocument[T], error) {
-	f, err := os.Open(path)
+	src, err := newSource(o)
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
 
-	var output bytes.Buffer
-	var meta T
-	if mdErr := markdown.Parse(f, &output, &meta); mdErr != nil {
-		return nil, mdErr
+	matched, err := src.glob(ctx, append([]string{glob}, o.patterns...))
+	if err != nil {
+		return nil, err
 	}
 
-	return &MarkdownDocument[T]{
-		FrontMatter: meta,
-		Body:        output.Bytes(),
-	}, nil
+	executions := concurrent.RunAllStreamContext(
+		ctx,
+		newMarkdownTasks[T](src, o, matched),
+		concurrent.WithMaxConcurrency(o.concurrency),
+	)
+
+	// Sized like the underlying channel so that forwarding never blocks.
+	resultChan := make(chan Result[T], len(matched))
+	go func() {
+		defer close(resultChan)
+		for execution := range executions {
+			if !errors.Is(execution.Result.Err, errFilteredOut) {
+				resultChan <- newResult(execution)
+			}
+		}
+	}()
+	return resultChan, nil
 }
 
-// runGlob executes glob pattern matching while respecting Git ignore rules.
-// It filters out files that are excluded by .gitignore, global Git excludes,
-// or local Git excludes, ensuring only relevant files are processed.
+// GlobFS is like Glob but discovers and reads Markdown files from fsys instead of the OS file system.
+// It is a shorthand for Glob(pattern, append(opts, WithFS(fsys))...), so it works with
+// embed.FS, fstest.MapFS, zip readers and any other io/fs.FS implementation.
+//
+// Patterns and reported paths use forward slashes and are relative to the root of fsys.
+// Git ignore rules are read from the `.gitignore` file at the root of fsys, if any.
+// Use WithGitIgnore(false) to disable filtering.
+//
+// Example usage:
 //
-// The function uses doublestar for advanced glob pattern support and
-// integrates with Git ignore functionality for seamless filtering.
-func runGlob(pattern string) ([]string, error) {
-	matched, err := doublestar.FilepathGlob(pattern)
+//	//go:embed content
+//	var content embed.FS
+//
+//	results, err := GlobFS[Article](content, "content/**/*.md")
+func GlobFS[T any](
+	fsys fs.FS,
+	glob string,
+	opts ...Option,
+) ([]Result[T], error) {
+	return Glob[T](glob, append(opts, WithFS(fsys))...)
+}
+
+// newMarkdownTasks creates a task for each matched path that reads and parses the Markdown file.
+func newMarkdownTasks[T any](src *source, o *options, paths []string) []concurrent.Task[*MarkdownDocument[T], string] {
+	return lo.Map(paths, func(p string) concurrent.Task[*MarkdownDocument[T], string] {
+		return newMarkdownTask[T](src, o, p)
+	})
+}
+
+// newMarkdownTask creates a task that reads and parses the Markdown file at p.
+func newMarkdownTask[T any](src *source, o *options, p string) concurrent.Task[*MarkdownDocument[T], string] {
+	return concurrent.Task[*MarkdownDocument[T], string]{
+		Metadata: p,
+		Run: func() (*MarkdownDocument[T], error) {
+			return processMarkdownFile[T](src, o, p)
+		},
+	}
+}
+
+// processMarkdownFile reads and parses a single Markdown file.
+// It extracts frontmatter metadata and returns the processed document, or errFilteredOut
+// if the document does not match the filter given with WithFilter.
+// This function is used internally by Glob for concurrent processing.
+func processMarkdownFile[T any](src *source, o *options, path string) (*MarkdownDocument[T], error) {
+	f, err := src.open(path)
 	if err != nil {
-		return nil, err
+		return nil, wrapIOError(err)
 	}
+	defer f.Close()
 
-	gi, err := gitignore.NewFromCWD()
+	doc, block, err := parseDocument[T](f, path, o)
+	if err != nil || o.filter == nil {
+		return doc, err
+	}
+	matched, err := o.filter.matchFile(src, path, block)
 	if err != nil {
 		return nil, err
 	}
-	if gi == nil {
-		return matched, nil
+	if !matched {
+		return nil, errFilteredOut
 	}
-
-	nonIgnoredFiles := lo.Filter(matched, func(p string) bool {
-		// gi is non-nil
-		return !gi.IsIgnored(p)
-	})
-	return nonIgnoredFiles, nil
+	return doc, nil
 }
@@ -1,9 +1,14 @@
 package mdfm_test
 
 import (
+	"context"
+	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -21,6 +26,15 @@ type testMetadata struct {
 func setupTestFiles(t *testing.T) string {
 	t.Helper()
 
+	tmpDir := writeTestFiles(t)
+	t.Chdir(tmpDir)
+
+	return tmpDir
+}
+
+func writeTestFiles(t *testing.T) string {
+	t.Helper()
+
 	tmpDir := t.TempDir()
 
 	testFiles := map[string]string{
@@ -80,8 +94,6 @@ Content`,
 		require.NoError(t, os.WriteFile(fullPath, []byte(content), 0644))
 	}
 
-	t.Chdir(tmpDir)
-
 	return tmpDir
 }
 
@@ -121,14 +133,14 @@ func TestGlobFrontMatter_BasicFunctionality(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tasks, err := mdfm.Glob[testMetadata](tt.pattern)
+			results, err := mdfm.Glob[testMetadata](tt.pattern)
 			require.NoError(t, err)
-			assert.Len(t, tasks, tt.expectedCount)
+			assert.Len(t, results, tt.expectedCount)
 
 			var actualTitles []string
-			for _, task := range tasks {
-				if task.Result.Err == nil && task.Result.Value.FrontMatter.Title != "" {
-					actualTitles = append(actualTitles, task.Result.Value.FrontMatter.Title)
+			for _, result := range results {
+				if result.Err == nil && result.Document.FrontMatter.Title != "" {
+					actualTitles = append(actualTitles, result.Document.FrontMatter.Title)
 				}
 			}
 
@@ -142,16 +154,16 @@ func TestGlobFrontMatter_BasicFunctionality(t *testing.T) {
 func TestGlobFrontMatter_ErrorHandling(t *testing.T) {
 	setupTestFiles(t)
 
-	tasks, err := mdfm.Glob[testMetadata]("**/*.md")
+	results, err := mdfm.Glob[testMetadata]("**/*.md")
 	require.NoError(t, err)
 
 	var errorCount int
 	var successCount int
 
-	for _, task := range tasks {
-		if task.Result.Err != nil {
+	for _, result := range results {
+		if result.Err != nil {
 			errorCount++
-			t.Logf("Error processing %s: %v", task.Metadata.Path, task.Result.Err)
+			t.Logf("Error processing %s: %v", result.Path, result.Err)
 		} else {
 			successCount++
 		}
@@ -164,56 +176,56 @@ func TestGlobFrontMatter_ErrorHandling(t *testing.T) {
 func TestGlobFrontMatter_FrontMatterParsing(t *testing.T) {
 	setupTestFiles(t)
 
-	tasks, err := mdfm.Glob[testMetadata]("blog/post1.md")
+	results, err := mdfm.Glob[testMetadata]("blog/post1.md")
 	require.NoError(t, err)
-	require.Len(t, tasks, 1)
+	require.Len(t, results, 1)
 
-	task := tasks[0]
-	require.NoError(t, task.Result.Err)
-	require.NotNil(t, task.Result.Value)
+	result := results[0]
+	require.NoError(t, result.Err)
+	require.NotNil(t, result.Document)
 
-	fm := task.Result.Value.FrontMatter
+	fm := result.Document.FrontMatter
 	assert.Equal(t, "First Post", fm.Title)
 	assert.Equal(t, "This is the first post", fm.Description)
 	assert.Equal(t, []string{"golang", "testing"}, fm.Tags)
 	assert.True(t, fm.Published)
 
-	assert.Contains(t, string(task.Result.Value.Body), "This is the content of the first post.")
-	assert.Equal(t, "blog/post1.md", task.Metadata.Path)
+	assert.Contains(t, string(result.Document.Body), "This is the content of the first post.")
+	assert.Equal(t, "blog/post1.md", result.Path)
 }
 
 func TestGlobFrontMatter_NoFrontMatter(t *testing.T) {
 	setupTestFiles(t)
 
-	tasks, err := mdfm.Glob[testMetadata]("no-frontmatter.md")
+	results, err := mdfm.Glob[testMetadata]("no-frontmatter.md")
 	require.NoError(t, err)
-	require.Len(t, tasks, 1)
+	require.Len(t, results, 1)
 
-	task := tasks[0]
-	require.NoError(t, task.Result.Err)
-	require.NotNil(t, task.Result.Value)
+	result := results[0]
+	require.NoError(t, result.Err)
+	require.NotNil(t, result.Document)
 
-	fm := task.Result.Value.FrontMatter
+	fm := result.Document.FrontMatter
 	assert.Empty(t, fm.Title)
 	assert.Empty(t, fm.Description)
 	assert.Empty(t, fm.Tags)
 	assert.False(t, fm.Published)
 
-	assert.Contains(t, string(task.Result.Value.Body), "# No Frontmatter")
+	assert.Contains(t, string(result.Document.Body), "# No Frontmatter")
 }
 
 func TestGlobFrontMatter_EmptyFile(t *testing.T) {
 	setupTestFiles(t)
 
-	tasks, err := mdfm.Glob[testMetadata]("empty.md")
+	results, err := mdfm.Glob[testMetadata]("empty.md")
 	require.NoError(t, err)
-	require.Len(t, tasks, 1)
+	require.Len(t, results, 1)
 
-	task := tasks[0]
-	require.NoError(t, task.Result.Err)
-	require.NotNil(t, task.Result.Value)
+	result := results[0]
+	require.NoError(t, result.Err)
+	require.NotNil(t, result.Document)
 
-	assert.Empty(t, task.Result.Value.Body)
+	assert.Empty(t, result.Document.Body)
 }
 
 func TestGlobFrontMatter_GitIgnoreRespect(t *testing.T) {
@@ -234,12 +246,12 @@ ignored/
 	ignoredInDir := filepath.Join(ignoredDir, "test.md")
 	require.NoError(t, os.WriteFile(ignoredInDir, []byte("# Ignored"), 0644))
 
-	tasks, err := mdfm.Glob[testMetadata]("**/*")
+	results, err := mdfm.Glob[testMetadata]("**/*")
 	require.NoError(t, err)
 
 	var paths []string
-	for _, task := range tasks {
-		paths = append(paths, task.Metadata.Path)
+	for _, result := range results {
+		paths = append(paths, result.Path)
 	}
 
 	assert.NotContains(t, paths, "blog/draft.md")
@@ -257,13 +269,13 @@ func TestGlobFrontMatter_InvalidGlobPattern(t *testing.T) {
 func TestGlobFrontMatter_ConcurrentProcessing(t *testing.T) {
 	setupTestFiles(t)
 
-	tasks, err := mdfm.Glob[testMetadata]("**/*.md")
+	results, err := mdfm.Glob[testMetadata]("**/*.md")
 	require.NoError(t, err)
 
-	assert.Greater(t, len(tasks), 1, "Should process multiple files")
+	assert.Greater(t, len(results), 1, "Should process multiple files")
 
-	for _, task := range tasks {
-		assert.NotEmpty(t, task.Metadata.Path, "Each result should have a path")
+	for _, result := range results {
+		assert.NotEmpty(t, result.Path, "Each result should have a path")
 	}
 }
 
@@ -271,14 +283,14 @@ func TestGlobFrontMatter_DifferentMetadataTypes(t *testing.T) {
 	setupTestFiles(t)
 
 	t.Run("map[string]any", func(t *testing.T) {
-		tasks, err := mdfm.Glob[map[string]any]("blog/post1.md")
+		results, err := mdfm.Glob[map[string]any]("blog/post1.md")
 		require.NoError(t, err)
-		require.Len(t, tasks, 1)
+		require.Len(t, results, 1)
 
-		task := tasks[0]
-		require.NoError(t, task.Result.Err)
+		result := results[0]
+		require.NoError(t, result.Err)
 
-		fm := task.Result.Value.FrontMatter
+		fm := result.Document.FrontMatter
 		assert.Equal(t, "First Post", fm["title"])
 		assert.Equal(t, true, fm["published"])
 	})
@@ -288,14 +300,259 @@ func TestGlobFrontMatter_DifferentMetadataTypes(t *testing.T) {
 			Title string `yaml:"title"`
 		}
 
-		tasks, err := mdfm.Glob[simpleMetadata]("blog/post1.md")
+		results, err := mdfm.Glob[simpleMetadata]("blog/post1.md")
 		require.NoError(t, err)
-		require.Len(t, tasks, 1)
+		require.Len(t, results, 1)
 
-		task := tasks[0]
-		require.NoError(t, task.Result.Err)
+		result := results[0]
+		require.NoError(t, result.Err)
 
-		fm := task.Result.Value.FrontMatter
+		fm := result.Document.FrontMatter
 		assert.Equal(t, "First Post", fm.Title)
 	})
 }
+
+// cancelOnOpenFS cancels a context once a Markdown file is opened, after discovery.
+type cancelOnOpenFS struct {
+	fstest.MapFS
+
+	cancel context.CancelFunc
+}
+
+func (fsys cancelOnOpenFS) Open(name string) (fs.File, error) {
+	if strings.HasSuffix(name, ".md") {
+		fsys.cancel()
+	}
+	return fsys.MapFS.Open(name)
+}
+
+func newCancelOnOpenFS(cancel context.CancelFunc) cancelOnOpenFS {
+	return cancelOnOpenFS{
+		MapFS: fstest.MapFS{
+			"a.md": {Data: []byte("---\ntitle: A\n---\n")},
+			"b.md": {Data: []byte("---\ntitle: B\n---\n")},
+			"c.md": {Data: []byte("---\ntitle: C\n---\n")},
+		},
+		cancel: cancel,
+	}
+}
+
+func TestGlobContext_Canceled(t *testing.T) {
+	t.Run("during discovery", func(t *testing.T) {
+		setupTestFiles(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		results, err := mdfm.GlobContext[testMetadata](ctx, "**/*.md")
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, results)
+	})
+
+	t.Run("while reading", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		results, err := mdfm.GlobContext[testMetadata](ctx, "*.md",
+			mdfm.WithFS(newCancelOnOpenFS(cancel)), mdfm.WithConcurrency(1))
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+
+		var canceled int
+		for _, result := range results {
+			if errors.Is(result.Err, context.Canceled) {
+				canceled++
+				continue
+			}
+			require.NoError(t, result.Err)
+		}
+		assert.Equal(t, 2, canceled, "only the first file should be read")
+	})
+}
+
+func TestGlobStreamContext_Canceled(t *testing.T) {
+	t.Run("during discovery", func(t *testing.T) {
+		setupTestFiles(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		resultChan, err := mdfm.GlobStreamContext[testMetadata](ctx, "**/*.md")
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, resultChan)
+	})
+
+	t.Run("while reading", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		resultChan, err := mdfm.GlobStreamContext[testMetadata](ctx, "*.md",
+			mdfm.WithFS(newCancelOnOpenFS(cancel)), mdfm.WithConcurrency(1))
+		require.NoError(t, err)
+
+		var count, canceled int
+		for result := range resultChan {
+			count++
+			if errors.Is(result.Err, context.Canceled) {
+				canceled++
+				continue
+			}
+			require.NoError(t, result.Err)
+		}
+		assert.Equal(t, 3, count)
+		assert.Equal(t, 2, canceled, "only the first file should be read")
+	})
+}
+
+func TestGlob_WithRoot(t *testing.T) {
+	root := writeTestFiles(t)
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".gitignore"), []byte("blog/draft.md\n"), 0644))
+
+	// Resolve patterns from an unrelated working directory.
+	t.Chdir(t.TempDir())
+
+	results, err := mdfm.Glob[testMetadata]("blog/*.md", mdfm.WithRoot(root))
+	require.NoError(t, err)
+
+	var paths []string
+	for _, result := range results {
+		require.NoError(t, result.Err)
+		paths = append(paths, result.Path)
+	}
+	assert.ElementsMatch(t, []string{
+		filepath.Join("blog", "post1.md"),
+		filepath.Join("blog", "post2.md"),
+	}, paths)
+
+	resultChan, err := mdfm.GlobStream[testMetadata]("docs/readme.md", mdfm.WithRoot(root))
+	require.NoError(t, err)
+
+	var titles []string
+	for result := range resultChan {
+		require.NoError(t, result.Err)
+		titles = append(titles, result.Document.FrontMatter.Title)
+	}
+	assert.Equal(t, []string{"README"}, titles)
+}
+
+func TestGlob_WithRootInvalidPattern(t *testing.T) {
+	root := writeTestFiles(t)
+
+	_, err := mdfm.Glob[testMetadata]("[invalid", mdfm.WithRoot(root))
+	assert.Error(t, err)
+}
+
+func TestGlob_WithConcurrency(t *testing.T) {
+	setupTestFiles(t)
+
+	for _, n := range []int{-1, 0, 1, 3} {
+		results, err := mdfm.Glob[testMetadata]("**/*.md", mdfm.WithConcurrency(n))
+		require.NoError(t, err)
+		assert.Len(t, results, 7, "concurrency %d", n)
+	}
+}
+
+func TestGlobFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		".gitignore": {Data: []byte("drafts/\n")},
+		"content/post.md": {Data: []byte(`---
+title: Embedded Post
+tags: [fs]
+---
+Embedded body`)},
+		"content/other.txt":     {Data: []byte("not markdown")},
+		"content/drafts/wip.md": {Data: []byte("---\ntitle: WIP\n---\n")},
+	}
+
+	t.Run("reads files from fsys", func(t *testing.T) {
+		results, err := mdfm.GlobFS[testMetadata](fsys, "content/**/*.md")
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+
+		result := results[0]
+		require.NoError(t, result.Err)
+		assert.Equal(t, "content/post.md", result.Path)
+		assert.Equal(t, "Embedded Post", result.Document.FrontMatter.Title)
+		assert.Equal(t, []string{"fs"}, result.Document.FrontMatter.Tags)
+		assert.Equal(t, "Embedded body", result.Document.BodyString())
+	})
+
+	t.Run("git ignore filtering can be disabled", func(t *testing.T) {
+		results, err := mdfm.GlobFS[testMetadata](fsys, "content/**/*.md", mdfm.WithGitIgnore(false))
+		require.NoError(t, err)
+
+		var paths []string
+		for _, result := range results {
+			paths = append(paths, result.Path)
+		}
+		assert.ElementsMatch(t, []string{"content/post.md", "content/drafts/wip.md"}, paths)
+	})
+
+	t.Run("root is resolved inside fsys", func(t *testing.T) {
+		resultChan, err := mdfm.GlobStream[testMetadata]("*.md", mdfm.WithFS(fsys), mdfm.WithRoot("content"))
+		require.NoError(t, err)
+
+		var paths []string
+		for result := range resultChan {
+			require.NoError(t, result.Err)
+			paths = append(paths, result.Path)
+		}
+		assert.Equal(t, []string{"post.md"}, paths)
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		_, err := mdfm.GlobFS[testMetadata](fsys, "[invalid")
+		assert.Error(t, err)
+	})
+}
+
+func TestGlob_WithPatterns(t *testing.T) {
+	setupTestFiles(t)
+
+	paths := func(t *testing.T, pattern string, patterns ...string) []string {
+		t.Helper()
+
+		results, err := mdfm.Glob[testMetadata](pattern, mdfm.WithPatterns(patterns...))
+		require.NoError(t, err)
+
+		var ps []string
+		for _, result := range results {
+			ps = append(ps, filepath.ToSlash(result.Path))
+		}
+		return ps
+	}
+
+	t.Run("multiple patterns", func(t *testing.T) {
+		assert.ElementsMatch(t,
+			[]string{"blog/post1.md", "blog/post2.md", "blog/draft.md", "docs/readme.md"},
+			paths(t, "blog/*.md", "docs/*.md"),
+		)
+	})
+
+	t.Run("duplicates are removed", func(t *testing.T) {
+		assert.ElementsMatch(t,
+			[]string{"blog/post1.md", "blog/post2.md", "blog/draft.md"},
+			paths(t, "blog/*.md", "blog/post*.md", "**/post1.md"),
+		)
+	})
+
+	t.Run("negated patterns exclude matches", func(t *testing.T) {
+		assert.ElementsMatch(t,
+			[]string{"blog/post1.md", "blog/post2.md", "docs/readme.md"},
+			paths(t, "!**/draft.md", "blog/*.md", "docs/*.md"),
+		)
+		assert.ElementsMatch(t,
+			[]string{"docs/readme.md"},
+			paths(t, "**/*.md", "!blog/**", "!*.md"),
+		)
+	})
+
+	t.Run("only exclusions match nothing", func(t *testing.T) {
+		assert.Empty(t, paths(t, "!blog/**"))
+	})
+
+	t.Run("invalid exclusion", func(t *testing.T) {
+		_, err := mdfm.Glob[testMetadata]("**/*.md", mdfm.WithPatterns("![invalid"))
+		assert.Error(t, err)
+	})
+}
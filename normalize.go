@@ -0,0 +1,28 @@
+package mdfm
+
+import (
+	"github.com/sushichan044/mdfm/internal/tree"
+)
+
+// NormalizeJSON converts decoded frontmatter, such as the FrontMatter of a
+// MarkdownDocument[map[string]any], into values that encoding/json can always encode,
+// with the same representation regardless of the source format:
+//
+//   - mappings become map[string]any; non-string keys (e.g. YAML `1: one`) are formatted as strings,
+//   - sequences become []any, integers int64 and other numbers float64,
+//   - datetimes become RFC 3339 strings; TOML local dates, times and datetimes keep their
+//     local form, e.g. "2006-01-02",
+//   - NaN and infinite numbers become the strings "NaN", "Infinity" and "-Infinity".
+//
+// Strings, booleans and nil are returned as is, as are values of other types such as structs.
+//
+// Example:
+//
+//	doc, err := mdfm.ParseFile[map[string]any]("post.md")
+//	if err != nil {
+//		return err
+//	}
+//	out, err := json.Marshal(mdfm.NormalizeJSON(doc.FrontMatter))
+func NormalizeJSON(v any) any {
+	return tree.JSONSafe(tree.Normalize(v))
+}
@@ -0,0 +1,120 @@
+package mdfm_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/mdfm"
+)
+
+func TestNormalizeJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "yaml",
+			input: `---
+title: Hello
+count: 3
+ratio: 0.5
+published: 2024-01-02
+updated: !!timestamp 2024-01-02T03:04:05+09:00
+missing: .nan
+limit: .inf
+floor: -.inf
+seo:
+  description: Nested
+codes:
+  1: one
+  true: yes
+list:
+  - a: 1
+---
+`,
+			expected: `{
+				"title": "Hello",
+				"count": 3,
+				"ratio": 0.5,
+				"published": "2024-01-02",
+				"updated": "2024-01-02T03:04:05+09:00",
+				"missing": "NaN",
+				"limit": "Infinity",
+				"floor": "-Infinity",
+				"seo": {"description": "Nested"},
+				"codes": {"1": "one", "true": true},
+				"list": [{"a": 1}]
+			}`,
+		},
+		{
+			name: "toml",
+			input: `+++
+title = "Hello"
+count = 3
+ratio = 0.5
+updated = 2024-01-02T03:04:05Z
+published = 2024-01-02
+at = 10:30:00
+local = 2024-01-02T10:30:00
+
+[seo]
+description = "Nested"
+
+[[list]]
+a = 1
++++
+`,
+			expected: `{
+				"title": "Hello",
+				"count": 3,
+				"ratio": 0.5,
+				"updated": "2024-01-02T03:04:05Z",
+				"published": "2024-01-02",
+				"at": "10:30:00",
+				"local": "2024-01-02T10:30:00",
+				"seo": {"description": "Nested"},
+				"list": [{"a": 1}]
+			}`,
+		},
+		{
+			name: "json",
+			input: `{
+  "title": "Hello",
+  "count": 3,
+  "ratio": 0.5,
+  "seo": {"description": "Nested"},
+  "list": [{"a": 1}]
+}
+
+`,
+			expected: `{
+				"title": "Hello",
+				"count": 3,
+				"ratio": 0.5,
+				"seo": {"description": "Nested"},
+				"list": [{"a": 1}]
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := mdfm.ParseBytes[map[string]any]([]byte(tt.input))
+			require.NoError(t, err)
+
+			out, err := json.Marshal(mdfm.NormalizeJSON(doc.FrontMatter))
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(out))
+		})
+	}
+
+	t.Run("values of other types are kept", func(t *testing.T) {
+		doc := testMetadata{Title: "Title"}
+		assert.Equal(t, doc, mdfm.NormalizeJSON(doc))
+		assert.Nil(t, mdfm.NormalizeJSON(nil))
+	})
+}
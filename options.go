@@ -0,0 +1,230 @@
+package mdfm
+
+import (
+	"io/fs"
+
+	"github.com/sushichan044/mdfm/internal/markdown"
+)
+
+type (
+	// Option configures how Glob, GlobStream and their variants discover and parse Markdown files.
+	// Options that only affect discovery (such as WithConcurrency) are ignored by Parse and its variants.
+	Option func(*options)
+
+	options struct {
+		concurrency int64
+		root        string
+		fsys        fs.FS
+		gitIgnore   bool
+		patterns    []string
+		positions   bool
+		skipBody    bool
+
+		requireFrontMatter bool
+		strictDecoding     bool
+		unifiedDecoding    bool
+		schema             *Schema
+		filter             *Filter
+
+		dryRun      bool
+		mergeValues bool
+
+		customFormats []Format
+		// formats are the formats to detect, resolved by newOptions. nil means the built-in formats.
+		formats []*markdown.Format
+
+		// err is the first error found in the options, e.g. an invalid format, returned by newOptions.
+		err error
+	}
+)
+
+const (
+	defaultConcurrency = 10
+
+	// UnifiedTag is the struct tag read by WithUnifiedDecoding.
+	UnifiedTag = "mdfm"
+)
+
+// WithConcurrency sets the maximum number of files that are read and parsed at the same time.
+// The default is 10. Values less than 1 are ignored.
+func WithConcurrency(n int) Option {
+	return func(o *options) {
+		if n >= 1 {
+			o.concurrency = int64(n)
+		}
+	}
+}
+
+// WithRoot sets the directory that glob patterns are resolved against.
+//
+// By default patterns are resolved against the current working directory. With WithRoot,
+// patterns are matched inside dir instead, Git ignore rules are loaded from dir, and the
+// reported paths are relative to dir. The process working directory is never changed,
+// so it is safe to scan several directories concurrently.
+func WithRoot(dir string) Option {
+	return func(o *options) {
+		o.root = dir
+	}
+}
+
+// WithFS makes Markdown files be discovered and read from fsys instead of the OS file system.
+// Patterns and reported paths use forward slashes and are relative to the root of fsys,
+// following the io/fs conventions. When combined with WithRoot, dir is resolved inside fsys.
+//
+// Git ignore rules are read from the `.gitignore` file at the root of fsys (if any);
+// global and repository-local Git excludes do not apply. Use WithGitIgnore(false)
+// to disable filtering entirely.
+func WithFS(fsys fs.FS) Option {
+	return func(o *options) {
+		o.fsys = fsys
+	}
+}
+
+// WithGitIgnore enables or disables filtering of files excluded by Git ignore rules.
+// Filtering is enabled by default.
+func WithGitIgnore(enabled bool) Option {
+	return func(o *options) {
+		o.gitIgnore = enabled
+	}
+}
+
+// WithPatterns adds glob patterns that are matched in addition to the pattern passed to Glob.
+//
+// Patterns prefixed with "!" exclude every file they match, regardless of the order in which
+// patterns are given. Files matched by more than one pattern are only processed once.
+//
+// Example:
+//
+//	Glob[Article]("docs/**/*.md", WithPatterns("blog/**/*.md", "!**/drafts/**"))
+func WithPatterns(patterns ...string) Option {
+	return func(o *options) {
+		o.patterns = append(o.patterns, patterns...)
+	}
+}
+
+// WithPositions records where each frontmatter key is defined in MarkdownDocument.Positions.
+// Positions are available for the YAML, TOML and JSON formats.
+func WithPositions() Option {
+	return func(o *options) {
+		o.positions = true
+	}
+}
+
+// WithoutBody makes files not be read past their frontmatter block, which makes scanning the
+// frontmatter of large files faster. MarkdownDocument.Body is nil, so the documents must not be
+// written back with Marshal; Update, Edit and RenameKey fail if it is given.
+func WithoutBody() Option {
+	return func(o *options) {
+		o.skipBody = true
+	}
+}
+
+// WithRequireFrontMatter makes documents without a frontmatter block fail with an error wrapping ErrNoFrontMatter
+// instead of being returned with a zero-valued FrontMatter.
+func WithRequireFrontMatter() Option {
+	return func(o *options) {
+		o.requireFrontMatter = true
+	}
+}
+
+// WithStrictDecoding makes documents whose frontmatter has keys that do not match any field of T
+// fail with *UnknownFieldsError, e.g. to catch typos such as "descripton" in CI.
+//
+// Keys are checked against the struct tags used by the format's decoder ("yaml", "toml" or "json"),
+// or the `mdfm` tag with WithUnifiedDecoding.
+// Maps, interfaces and types that implement their own unmarshaling accept any key.
+func WithStrictDecoding() Option {
+	return func(o *options) {
+		o.strictDecoding = true
+	}
+}
+
+// WithSchema makes documents whose frontmatter does not match schema fail with *ValidationError,
+// e.g. to enforce a frontmatter contract in CI:
+//
+//	schema, err := mdfm.CompileSchema("schemas/post.json")
+//	if err != nil {
+//		return err
+//	}
+//	results, err := mdfm.Glob[mdfm.OrderedMap]("posts/**/*.md", mdfm.WithSchema(schema))
+//
+// The frontmatter is validated as JSON, as returned by NormalizeJSON, regardless of its format
+// and of T. Documents without frontmatter are validated as an empty object.
+func WithSchema(schema *Schema) Option {
+	return func(o *options) {
+		o.schema = schema
+	}
+}
+
+// WithFilter makes Glob, GlobStream and their variants only return the documents that match filter:
+//
+//	filter, err := mdfm.CompileFilter(`draft == false && "go" in tags`)
+//	if err != nil {
+//		return err
+//	}
+//	results, err := mdfm.Glob[Article]("posts/**/*.md", mdfm.WithFilter(filter))
+//
+// The filter is evaluated against the frontmatter as written in the document, regardless of T.
+// Files that cannot be read or parsed are still reported. WithFilter is ignored by Parse and
+// its variants; use Filter.Match instead.
+func WithFilter(filter *Filter) Option {
+	return func(o *options) {
+		o.filter = filter
+	}
+}
+
+// WithUnifiedDecoding decodes the frontmatter the same way regardless of its format.
+//
+// By default, the frontmatter is handed to the decoder of its format, so a struct needs
+// `yaml`, `toml` and `json` tags to accept every format, and each decoder converts dates
+// and numbers differently. With WithUnifiedDecoding, the frontmatter is first converted
+// to a generic tree and then decoded into T using a single `mdfm` struct tag:
+//
+//	type Article struct {
+//		Title string    `mdfm:"title"`
+//		Date  time.Time `mdfm:"date"`
+//	}
+//
+// Keys are matched case-insensitively if there is no exact match, and fields without a tag
+// are matched by their name. Integers can be decoded into any numeric type, and time.Time
+// accepts YAML and TOML datetimes as well as RFC 3339 strings and plain dates ("2006-01-02");
+// dates without a zone, TOML local dates included, are interpreted as UTC. String fields also
+// accept booleans and numbers, such as "title: 2024".
+// Values that cannot be converted are reported as *ParseError at the position of their key.
+func WithUnifiedDecoding() Option {
+	return func(o *options) {
+		o.unifiedDecoding = true
+	}
+}
+
+// WithDryRun makes Edit and RenameKey report the changes they would make without writing any file.
+func WithDryRun() Option {
+	return func(o *options) {
+		o.dryRun = true
+	}
+}
+
+// WithMergeValues makes RenameKey combine the values of both keys when the new key is already
+// present, instead of failing with ErrKeyExists: lists are concatenated without duplicates,
+// mappings are merged key by key, and a single value is added to a list. Different single
+// values cannot be combined.
+func WithMergeValues() Option {
+	return func(o *options) {
+		o.mergeValues = true
+	}
+}
+
+func newOptions(opts ...Option) (*options, error) {
+	o := &options{
+		concurrency: defaultConcurrency,
+		gitIgnore:   true,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.err != nil {
+		return nil, o.err
+	}
+	o.formats = detectedFormats(o.customFormats)
+	return o, nil
+}
@@ -0,0 +1,22 @@
+package mdfm
+
+import (
+	"github.com/sushichan044/mdfm/internal/tree"
+)
+
+// OrderedMap is a frontmatter mapping that keeps its keys in source order.
+//
+// Use it as T instead of map[string]any when the order of the keys matters, e.g. to
+// generate indexes or to re-encode the frontmatter as JSON without sorting its keys:
+//
+//	results, err := Glob[OrderedMap]("**/*.md")
+//
+// Nested mappings are stored as *OrderedMap and sequences as []any. Integers are stored as int64
+// and other numbers as float64 regardless of the format. Order is preserved for YAML, TOML and
+// JSON frontmatter; it marshals to a JSON object with its keys in order.
+type OrderedMap = tree.OrderedMap
+
+// NewOrderedMap returns an empty OrderedMap.
+func NewOrderedMap() *OrderedMap {
+	return tree.NewOrderedMap()
+}
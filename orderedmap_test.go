@@ -0,0 +1,78 @@
+package mdfm_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/mdfm"
+)
+
+func TestOrderedMap(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "yaml",
+			input: "---\ntitle: T\ndate: 2024-01-02\nseo:\n  zeta: 1\n  alpha: 2\nauthors:\n  - name: A\n    age: 1\n---\nBody",
+		},
+		{
+			name: "toml",
+			input: "+++\ntitle = \"T\"\ndate = \"2024-01-02\"\n\n[seo]\nzeta = 1\nalpha = 2\n\n" +
+				"[[authors]]\nname = \"A\"\nage = 1\n+++\nBody",
+		},
+		{
+			name: "json",
+			input: "{\n  \"title\": \"T\",\n  \"date\": \"2024-01-02\",\n  \"seo\": {\"zeta\": 1, \"alpha\": 2},\n" +
+				"  \"authors\": [{\"name\": \"A\", \"age\": 1}]\n}\n\nBody",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := mdfm.ParseBytes[mdfm.OrderedMap]([]byte(tt.input))
+			require.NoError(t, err)
+
+			assert.Equal(t, []string{"title", "date", "seo", "authors"}, doc.FrontMatter.Keys())
+			assert.Equal(t, "Body", doc.BodyString())
+
+			out, err := json.Marshal(doc.FrontMatter)
+			require.NoError(t, err)
+			assert.Equal(t,
+				`{"title":"T","date":"2024-01-02","seo":{"zeta":1,"alpha":2},"authors":[{"name":"A","age":1}]}`,
+				string(out),
+			)
+		})
+	}
+
+	t.Run("with unified decoding and strict decoding", func(t *testing.T) {
+		doc, err := mdfm.ParseBytes[mdfm.OrderedMap](
+			[]byte("---\nb: 1\na: 2\n---\n"),
+			mdfm.WithUnifiedDecoding(),
+			mdfm.WithStrictDecoding(),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"b", "a"}, doc.FrontMatter.Keys())
+	})
+
+	t.Run("NormalizeJSON keeps the order", func(t *testing.T) {
+		doc, err := mdfm.ParseBytes[mdfm.OrderedMap]([]byte("---\nb: .nan\na: 2\n---\n"))
+		require.NoError(t, err)
+
+		out, err := json.Marshal(mdfm.NormalizeJSON(doc.FrontMatter))
+		require.NoError(t, err)
+		assert.Equal(t, `{"b":"NaN","a":2}`, string(out))
+	})
+}
+
+func TestNewOrderedMap(t *testing.T) {
+	om := mdfm.NewOrderedMap()
+	om.Set("title", "T")
+
+	out, err := json.Marshal(om)
+	require.NoError(t, err)
+	assert.Equal(t, `{"title":"T"}`, string(out))
+}
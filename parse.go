@@ -0,0 +1,132 @@
+package mdfm
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"reflect"
+
+	"github.com/sushichan044/mdfm/internal/markdown"
+)
+
+// Parse reads a single Markdown document from r and extracts its frontmatter.
+//
+// It is useful when the Markdown content does not come from the file system,
+// e.g. HTTP uploads, Git blobs or generated content. The document is parsed
+// exactly like the files found by Glob, so the same errors are returned for
+// the same input.
+//
+// Example usage:
+//
+//	doc, err := Parse[Article](req.Body)
+//	if err != nil {
+//		return fmt.Errorf("invalid document: %w", err)
+//	}
+//	fmt.Println(doc.FrontMatter.Title)
+//
+// Invalid frontmatter is reported as *ParseError, and failures to read r wrap ErrIO.
+// See WithRequireFrontMatter, WithStrictDecoding and WithSchema for stricter checks.
+func Parse[T any](r io.Reader, opts ...Option) (*MarkdownDocument[T], error) {
+	o, err := newOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, _, err := parseDocument[T](r, "", o)
+	return doc, err
+}
+
+// ParseBytes parses a single Markdown document held in memory.
+// See Parse for details.
+func ParseBytes[T any](data []byte, opts ...Option) (*MarkdownDocument[T], error) {
+	return Parse[T](bytes.NewReader(data), opts...)
+}
+
+// ParseFile reads and parses the Markdown file at path.
+// Unlike Glob, Git ignore rules are not consulted. See Parse for details.
+func ParseFile[T any](path string, opts ...Option) (*MarkdownDocument[T], error) {
+	o, err := newOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, wrapIOError(err)
+	}
+	defer f.Close()
+
+	doc, _, err := parseDocument[T](f, path, o)
+	return doc, err
+}
+
+// parseDocument parses a single Markdown document read from r, and returns it along with its
+// frontmatter block, which is nil if the document has none. path is only used for error reporting.
+func parseDocument[T any](r io.Reader, path string, o *options) (*MarkdownDocument[T], *markdown.Block, error) {
+	decode, tag := markdown.Unmarshal, ""
+	if o.unifiedDecoding {
+		decode, tag = markdown.UnifiedDecoder(UnifiedTag), UnifiedTag
+	}
+
+	var body bytes.Buffer
+	var output io.Writer = &body
+	if o.skipBody {
+		output = nil
+	}
+
+	var meta T
+	block, mdErr := markdown.ParseWith(r, output, &meta, markdown.Options{Formats: o.formats, Decode: decode})
+	if mdErr != nil {
+		return nil, nil, wrapParseError(path, mdErr)
+	}
+
+	doc := &MarkdownDocument[T]{
+		FrontMatter:   meta,
+		Body:          body.Bytes(),
+		BodyStartLine: 1,
+		origin:        origin{fieldTag: tag},
+	}
+	if block == nil {
+		if o.requireFrontMatter {
+			return nil, nil, noFrontMatterError(path)
+		}
+		if o.schema != nil {
+			if err := o.schema.validate(path, nil); err != nil {
+				return nil, nil, err
+			}
+		}
+		return doc, nil, nil
+	}
+
+	if o.strictDecoding {
+		unknown, err := markdown.UnknownKeys(block, reflect.TypeFor[T](), tag)
+		if err != nil {
+			return nil, nil, wrapParseError(path, err)
+		}
+		if len(unknown) > 0 {
+			return nil, nil, newUnknownFieldsError(path, block, unknown)
+		}
+	}
+	if o.schema != nil {
+		if err := o.schema.validate(path, block); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	doc.Format = block.Format.Name
+	doc.origin.format = block.Format.Name
+	doc.origin.head = block.Head
+	doc.origin.tail = block.Tail
+	if doc.origin.fieldTag == "" {
+		doc.origin.fieldTag = block.Format.FieldTag
+	}
+	doc.RawFrontMatter = block.Raw
+	doc.BodyStartLine = block.BodyLine
+	if o.positions {
+		doc.Positions = make(map[string]Position)
+		for key, pos := range markdown.KeyPositions(block) {
+			doc.Positions[key] = Position{Line: pos.Line, Column: pos.Column}
+		}
+	}
+	return doc, block, nil
+}
@@ -0,0 +1,255 @@
+package mdfm_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/mdfm"
+)
+
+const testDocument = `---
+title: In Memory
+tags: [a, b]
+published: true
+---
+# Hello
+
+Body text.`
+
+func TestParse(t *testing.T) {
+	doc, err := mdfm.Parse[testMetadata](strings.NewReader(testDocument))
+	require.NoError(t, err)
+
+	assert.Equal(t, "In Memory", doc.FrontMatter.Title)
+	assert.Equal(t, []string{"a", "b"}, doc.FrontMatter.Tags)
+	assert.True(t, doc.FrontMatter.Published)
+	assert.Equal(t, "# Hello\n\nBody text.", doc.BodyString())
+}
+
+func TestParseBytes(t *testing.T) {
+	t.Run("valid document", func(t *testing.T) {
+		doc, err := mdfm.ParseBytes[map[string]any]([]byte(testDocument))
+		require.NoError(t, err)
+
+		assert.Equal(t, "In Memory", doc.FrontMatter["title"])
+		assert.Equal(t, "# Hello\n\nBody text.", doc.BodyString())
+	})
+
+	t.Run("no frontmatter", func(t *testing.T) {
+		doc, err := mdfm.ParseBytes[testMetadata]([]byte("# Just content"))
+		require.NoError(t, err)
+
+		assert.Empty(t, doc.FrontMatter.Title)
+		assert.Equal(t, "# Just content", doc.BodyString())
+	})
+}
+
+func TestParseFile(t *testing.T) {
+	setupTestFiles(t)
+
+	t.Run("same result as Glob", func(t *testing.T) {
+		doc, err := mdfm.ParseFile[testMetadata](filepath.Join("blog", "post1.md"))
+		require.NoError(t, err)
+
+		results, err := mdfm.Glob[testMetadata]("blog/post1.md")
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		require.NoError(t, results[0].Err)
+
+		assert.Equal(t, results[0].Document, doc)
+	})
+
+	t.Run("same error as Glob", func(t *testing.T) {
+		_, err := mdfm.ParseFile[testMetadata]("invalid-frontmatter.md")
+		require.Error(t, err)
+
+		results, globErr := mdfm.Glob[testMetadata]("invalid-frontmatter.md")
+		require.NoError(t, globErr)
+		require.Len(t, results, 1)
+
+		assert.Equal(t, results[0].Err, err)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := mdfm.ParseFile[testMetadata]("does-not-exist.md")
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestParse_FormatAndRawFrontMatter(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		format string
+		raw    string
+	}{
+		{
+			name:   "yaml",
+			input:  "---\n# comment\ntitle: YAML\n---\nBody",
+			format: mdfm.FormatYAML,
+			raw:    "# comment\ntitle: YAML\n",
+		},
+		{
+			name:   "toml",
+			input:  "+++\ntitle = \"TOML\"\n+++\nBody",
+			format: mdfm.FormatTOML,
+			raw:    "title = \"TOML\"\n",
+		},
+		{
+			name:   "json",
+			input:  "{\n  \"title\": \"JSON\"\n}\n\nBody",
+			format: mdfm.FormatJSON,
+			raw:    "{\n  \"title\": \"JSON\"\n}\n",
+		},
+		{
+			name:   "json between delimiters",
+			input:  ";;;\n{\"title\": \"JSON\"}\n;;;\nBody",
+			format: mdfm.FormatJSON,
+			raw:    "{\"title\": \"JSON\"}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := mdfm.ParseBytes[testMetadata]([]byte(tt.input))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.format, doc.Format)
+			assert.Equal(t, tt.raw, string(doc.RawFrontMatter))
+			assert.Equal(t, "Body", doc.BodyString())
+		})
+	}
+
+	t.Run("no frontmatter", func(t *testing.T) {
+		doc, err := mdfm.ParseBytes[testMetadata]([]byte("Body"))
+		require.NoError(t, err)
+
+		assert.Empty(t, doc.Format)
+		assert.Nil(t, doc.RawFrontMatter)
+	})
+}
+
+func TestParse_Positions(t *testing.T) {
+	input := "\n---\ntitle: Hello\nseo:\n  description: Nested\n---\nBody"
+
+	t.Run("body start line is always set", func(t *testing.T) {
+		doc, err := mdfm.ParseBytes[map[string]any]([]byte(input))
+		require.NoError(t, err)
+
+		assert.Equal(t, 7, doc.BodyStartLine)
+		assert.Nil(t, doc.Positions)
+	})
+
+	t.Run("key positions with WithPositions", func(t *testing.T) {
+		doc, err := mdfm.ParseBytes[map[string]any]([]byte(input), mdfm.WithPositions())
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]mdfm.Position{
+			"title":           {Line: 3, Column: 1},
+			"seo":             {Line: 4, Column: 1},
+			"seo.description": {Line: 5, Column: 3},
+		}, doc.Positions)
+	})
+
+	t.Run("no frontmatter", func(t *testing.T) {
+		doc, err := mdfm.ParseBytes[map[string]any]([]byte("Body"), mdfm.WithPositions())
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, doc.BodyStartLine)
+		assert.Empty(t, doc.Positions)
+	})
+}
+
+func TestParse_WithoutBody(t *testing.T) {
+	doc, err := mdfm.ParseBytes[testMetadata]([]byte(testDocument), mdfm.WithoutBody())
+	require.NoError(t, err)
+
+	assert.Equal(t, "In Memory", doc.FrontMatter.Title)
+	assert.Nil(t, doc.Body)
+	assert.Equal(t, 6, doc.BodyStartLine)
+}
+
+func TestParse_UnifiedDecoding(t *testing.T) {
+	type article struct {
+		Title   string    `mdfm:"title"`
+		Date    time.Time `mdfm:"date"`
+		Version int       `mdfm:"version"`
+		SEO     struct {
+			Description string `mdfm:"description"`
+		} `mdfm:"seo"`
+	}
+
+	expected := article{
+		Title:   "Title",
+		Date:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Version: 2,
+	}
+	expected.SEO.Description = "Nested"
+
+	inputs := map[string]string{
+		"yaml": "---\ntitle: Title\ndate: 2024-01-02T03:04:05Z\nversion: 2\nseo:\n  description: Nested\n---\nBody",
+		"toml": "+++\ntitle = \"Title\"\ndate = 2024-01-02T03:04:05Z\nversion = 2\n[seo]\ndescription = \"Nested\"\n+++\nBody",
+		"json": "{\n  \"title\": \"Title\",\n  \"date\": \"2024-01-02T03:04:05Z\",\n  \"version\": 2,\n" +
+			"  \"seo\": {\"description\": \"Nested\"}\n}\n\nBody",
+	}
+
+	for format, input := range inputs {
+		t.Run(format, func(t *testing.T) {
+			doc, err := mdfm.ParseBytes[article]([]byte(input), mdfm.WithUnifiedDecoding())
+			require.NoError(t, err)
+
+			assert.Equal(t, format, doc.Format)
+			assert.Equal(t, expected, doc.FrontMatter)
+			assert.Equal(t, "Body", doc.BodyString())
+		})
+	}
+
+	t.Run("plain scalars decode into strings", func(t *testing.T) {
+		input := "---\ntitle: 2024\nseo:\n  description: yes\n---\nBody"
+		doc, err := mdfm.ParseBytes[article]([]byte(input), mdfm.WithUnifiedDecoding())
+		require.NoError(t, err)
+
+		assert.Equal(t, "2024", doc.FrontMatter.Title)
+		assert.Equal(t, "true", doc.FrontMatter.SEO.Description)
+	})
+
+	t.Run("local dates decode as UTC", func(t *testing.T) {
+		docs := map[string]string{
+			"yaml": "---\ndate: 2024-01-02\n---\nBody",
+			"toml": "+++\ndate = 2024-01-02\n+++\nBody",
+			"json": "{\n  \"date\": \"2024-01-02\"\n}\n\nBody",
+		}
+		for format, input := range docs {
+			doc, err := mdfm.ParseBytes[article]([]byte(input), mdfm.WithUnifiedDecoding())
+			require.NoError(t, err, format)
+			assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), doc.FrontMatter.Date, format)
+		}
+	})
+
+	t.Run("conversion errors are reported at the key", func(t *testing.T) {
+		input := "---\ntitle: Title\nversion: latest\n---\nBody"
+		_, err := mdfm.ParseBytes[article]([]byte(input), mdfm.WithUnifiedDecoding())
+
+		var parseErr *mdfm.ParseError
+		require.ErrorAs(t, err, &parseErr)
+		assert.Equal(t, 3, parseErr.Line)
+		assert.Equal(t, 1, parseErr.Column)
+		assert.Equal(t, "version: latest", parseErr.Snippet)
+		assert.Equal(t, `3:1: invalid yaml frontmatter: version: cannot decode string "latest" into int`, parseErr.Error())
+	})
+
+	t.Run("strict decoding uses the mdfm tag", func(t *testing.T) {
+		input := "---\ntitle: Title\nversoin: 2\n---\nBody"
+		_, err := mdfm.ParseBytes[article]([]byte(input), mdfm.WithUnifiedDecoding(), mdfm.WithStrictDecoding())
+
+		var unknownErr *mdfm.UnknownFieldsError
+		require.ErrorAs(t, err, &unknownErr)
+		assert.Equal(t, []mdfm.UnknownField{{Key: "versoin", Line: 3, Column: 1}}, unknownErr.Fields)
+	})
+}
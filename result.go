@@ -0,0 +1,66 @@
+package mdfm
+
+import (
+	"github.com/sushichan044/mdfm/internal/concurrent"
+)
+
+// Result is the outcome of reading and parsing a single Markdown file found by Glob,
+// GlobStream or one of their variants.
+//
+// Exactly one of Document and Err is set.
+type Result[T any] struct {
+	// Path is the file system path to the markdown file, relative to the
+	// current working directory when Glob was called, or to the directory
+	// given with WithRoot or the root of the fs.FS given with WithFS.
+	Path string
+
+	// Document is the parsed Markdown document. It is nil if Err is non-nil.
+	Document *MarkdownDocument[T]
+
+	// Err is the error that occurred while reading or parsing the file, if any.
+	Err error
+}
+
+// Partition splits results into the ones that were parsed successfully and the ones that failed.
+// The relative order of results is preserved in both slices.
+func Partition[T any](results []Result[T]) ([]Result[T], []Result[T]) {
+	var succeeded, failed []Result[T]
+	for _, r := range results {
+		if r.Err != nil {
+			failed = append(failed, r)
+			continue
+		}
+		succeeded = append(succeeded, r)
+	}
+	return succeeded, failed
+}
+
+// Documents returns the documents of all successful results, in order.
+func Documents[T any](results []Result[T]) []*MarkdownDocument[T] {
+	var docs []*MarkdownDocument[T]
+	for _, r := range results {
+		if r.Err == nil {
+			docs = append(docs, r.Document)
+		}
+	}
+	return docs
+}
+
+// Errors returns the errors of all failed results, in order.
+// Use Partition instead if you also need the paths of the failed files.
+func Errors[T any](results []Result[T]) []error {
+	var errs []error
+	for _, r := range results {
+		if r.Err != nil {
+			errs = append(errs, r.Err)
+		}
+	}
+	return errs
+}
+
+func newResult[T any](execution concurrent.TaskExecution[*MarkdownDocument[T], string]) Result[T] {
+	if execution.Result.Err != nil {
+		return Result[T]{Path: execution.Metadata, Err: execution.Result.Err}
+	}
+	return Result[T]{Path: execution.Metadata, Document: execution.Result.Value}
+}
@@ -0,0 +1,54 @@
+package mdfm_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sushichan044/mdfm"
+)
+
+func testResults() []mdfm.Result[testMetadata] {
+	return []mdfm.Result[testMetadata]{
+		{Path: "a.md", Document: &mdfm.MarkdownDocument[testMetadata]{FrontMatter: testMetadata{Title: "A"}}},
+		{Path: "b.md", Err: errors.New("boom")},
+		{Path: "c.md", Document: &mdfm.MarkdownDocument[testMetadata]{FrontMatter: testMetadata{Title: "C"}}},
+		{Path: "d.md", Err: errors.New("bang")},
+	}
+}
+
+func TestPartition(t *testing.T) {
+	succeeded, failed := mdfm.Partition(testResults())
+
+	var succeededPaths, failedPaths []string
+	for _, r := range succeeded {
+		succeededPaths = append(succeededPaths, r.Path)
+	}
+	for _, r := range failed {
+		failedPaths = append(failedPaths, r.Path)
+	}
+
+	assert.Equal(t, []string{"a.md", "c.md"}, succeededPaths)
+	assert.Equal(t, []string{"b.md", "d.md"}, failedPaths)
+}
+
+func TestDocuments(t *testing.T) {
+	docs := mdfm.Documents(testResults())
+
+	var titles []string
+	for _, doc := range docs {
+		titles = append(titles, doc.FrontMatter.Title)
+	}
+	assert.Equal(t, []string{"A", "C"}, titles)
+}
+
+func TestErrors(t *testing.T) {
+	errs := mdfm.Errors(testResults())
+
+	assert.Len(t, errs, 2)
+	assert.EqualError(t, errs[0], "boom")
+	assert.EqualError(t, errs[1], "bang")
+
+	assert.Empty(t, mdfm.Errors[testMetadata](nil))
+}
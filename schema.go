@@ -0,0 +1,156 @@
+package mdfm
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+
+	"github.com/sushichan044/mdfm/internal/markdown"
+	"github.com/sushichan044/mdfm/internal/tree"
+)
+
+type (
+	// Schema is a compiled JSON Schema that the frontmatter of documents is validated against,
+	// see WithSchema.
+	Schema struct {
+		schema *jsonschema.Schema
+	}
+
+	// ValidationError is reported by WithSchema when the frontmatter of a document does not
+	// match the schema.
+	ValidationError struct {
+		// Path is the path of the document, or empty if it was not read from a file.
+		Path string
+
+		// Format is the name of the frontmatter format, e.g. "yaml", "toml" or "json".
+		// It is empty if the document has no frontmatter.
+		Format string
+
+		// Violations are the reasons why the frontmatter does not match, in the order they
+		// appear in the document.
+		Violations []Violation
+	}
+
+	// Violation is a single reason why frontmatter does not match a schema.
+	Violation struct {
+		// Key is the path of the value that does not match, e.g. "seo.description" or
+		// "authors.0.name". It is empty for the frontmatter as a whole, e.g. when a
+		// required key is missing.
+		Key string
+
+		// Message describes the problem, e.g. "missing property 'title'".
+		Message string
+
+		// Line is the 1-based line number in the document where the key is defined, or 0 if unknown.
+		Line int
+
+		// Column is the 1-based column where the key is defined, or 0 if unknown.
+		Column int
+	}
+)
+
+// CompileSchema reads and compiles the JSON Schema file at path.
+//
+// Schemas are interpreted as draft 2020-12 unless they declare another draft with "$schema",
+// and "format" keywords are asserted, so that e.g. {"format": "date"} rejects "tomorrow".
+// References to other files are resolved relative to path.
+func CompileSchema(path string) (*Schema, error) {
+	c := jsonschema.NewCompiler()
+	c.DefaultDraft(jsonschema.Draft2020)
+	c.AssertFormat()
+
+	schema, err := c.Compile(path)
+	if err != nil {
+		return nil, fmt.Errorf("mdfm: invalid schema %s: %w", path, err)
+	}
+	return &Schema{schema: schema}, nil
+}
+
+func (e *ValidationError) Error() string {
+	var first Violation
+	if len(e.Violations) > 0 {
+		first = e.Violations[0]
+	}
+
+	msg := "frontmatter does not match the schema: " + first.String()
+	if n := len(e.Violations) - 1; n == 1 {
+		msg += " (and 1 more violation)"
+	} else if n > 1 {
+		msg += fmt.Sprintf(" (and %d more violations)", n)
+	}
+	return withLocation(e.Path, first.Line, first.Column, msg)
+}
+
+// String returns the message of the violation, prefixed with its key if any.
+func (v Violation) String() string {
+	if v.Key == "" {
+		return v.Message
+	}
+	return v.Key + ": " + v.Message
+}
+
+// validate checks the frontmatter of block against the schema, as converted by tree.JSONSafe, so
+// that TOML local dates are validated as "2024-01-02". Documents without frontmatter are
+// validated as an empty mapping.
+func (s *Schema) validate(path string, block *markdown.Block) error {
+	var value any = map[string]any{}
+	if block != nil {
+		generic, err := markdown.Generic(block)
+		if err != nil {
+			return wrapParseError(path, err)
+		}
+		value = tree.JSONSafe(generic)
+	}
+
+	err := s.schema.Validate(value)
+	var schemaErr *jsonschema.ValidationError
+	if !errors.As(err, &schemaErr) {
+		return err
+	}
+
+	validationErr := &ValidationError{Path: path}
+	var positions map[string]markdown.Position
+	if block != nil {
+		validationErr.Format = block.Format.Name
+		positions = markdown.KeyPositions(block)
+	}
+	for _, leaf := range violationLeaves(schemaErr) {
+		key := strings.Join(leaf.InstanceLocation, ".")
+		pos := positions[key]
+		validationErr.Violations = append(validationErr.Violations, Violation{
+			Key:     key,
+			Message: leaf.BasicOutput().Error.String(),
+			Line:    pos.Line,
+			Column:  pos.Column,
+		})
+	}
+	slices.SortStableFunc(validationErr.Violations, func(a, b Violation) int {
+		// Violations of the whole frontmatter go first, and keys without a known position last.
+		if (a.Key == "") != (b.Key == "") {
+			return cmp.Compare(len(a.Key), len(b.Key))
+		}
+		if (a.Line == 0) != (b.Line == 0) {
+			return cmp.Compare(b.Line, a.Line)
+		}
+		return cmp.Or(cmp.Compare(a.Line, b.Line), cmp.Compare(a.Column, b.Column))
+	})
+	return validationErr
+}
+
+// violationLeaves returns the errors of err that have no underlying causes, i.e. the
+// keywords that failed, as opposed to the subschemas that contain them.
+func violationLeaves(err *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(err.Causes) == 0 {
+		return []*jsonschema.ValidationError{err}
+	}
+
+	var leaves []*jsonschema.ValidationError
+	for _, cause := range err.Causes {
+		leaves = append(leaves, violationLeaves(cause)...)
+	}
+	return leaves
+}
@@ -0,0 +1,119 @@
+package mdfm_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/mdfm"
+)
+
+const testSchema = `{
+  "type": "object",
+  "required": ["title"],
+  "properties": {
+    "title": {"type": "string", "minLength": 1},
+    "date": {"type": "string", "format": "date"},
+    "tags": {"type": "array", "items": {"type": "string"}}
+  }
+}`
+
+func compileTestSchema(t *testing.T, schema string) *mdfm.Schema {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "schema.json")
+	require.NoError(t, os.WriteFile(path, []byte(schema), 0o600))
+
+	compiled, err := mdfm.CompileSchema(path)
+	require.NoError(t, err)
+	return compiled
+}
+
+func TestWithSchema(t *testing.T) {
+	schema := compileTestSchema(t, testSchema)
+
+	tests := []struct {
+		name       string
+		input      string
+		violations []mdfm.Violation
+	}{
+		{
+			name:  "valid yaml",
+			input: "---\ntitle: Hello\ndate: 2024-01-02\ntags: [go]\n---\n",
+		},
+		{
+			name:  "valid toml with a local date",
+			input: "+++\ntitle = \"Hello\"\ndate = 2024-01-02\n+++\n",
+		},
+		{
+			name:  "toml local datetimes keep their local form",
+			input: "+++\ntitle = \"Hello\"\ndate = 2024-01-02T10:00:00\n+++\n",
+			violations: []mdfm.Violation{
+				{Key: "date", Message: "'2024-01-02T10:00:00' is not valid date: parsing time \"2024-01-02T10:00:00\": extra text: \"T10:00:00\"", Line: 3, Column: 1},
+			},
+		},
+		{
+			name:  "violations are reported in document order",
+			input: "---\ntags:\n  - go\n  - 1\ntitle: \"\"\n---\n",
+			violations: []mdfm.Violation{
+				{Key: "tags.1", Message: "got number, want string", Line: 4, Column: 5},
+				{Key: "title", Message: "minLength: got 0, want 1", Line: 5, Column: 1},
+			},
+		},
+		{
+			name:  "formats are asserted",
+			input: "{\n  \"title\": \"Hello\",\n  \"date\": \"tomorrow\"\n}\n",
+			violations: []mdfm.Violation{
+				{Key: "date", Message: "'tomorrow' is not valid date: parsing time \"tomorrow\" as \"2006-01-02\": cannot parse \"tomorrow\" as \"2006\"", Line: 3, Column: 3},
+			},
+		},
+		{
+			name:  "documents without frontmatter are empty objects",
+			input: "# Hello\n",
+			violations: []mdfm.Violation{
+				{Message: "missing property 'title'"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := mdfm.ParseBytes[mdfm.OrderedMap]([]byte(tt.input), mdfm.WithSchema(schema))
+			if tt.violations == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			var validationErr *mdfm.ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tt.violations, validationErr.Violations)
+		})
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	setupTestFiles(t)
+	schema := compileTestSchema(t, `{"required": ["title", "slug"]}`)
+
+	results, err := mdfm.Glob[testMetadata]("no-frontmatter.md", mdfm.WithSchema(schema))
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	var validationErr *mdfm.ValidationError
+	require.ErrorAs(t, results[0].Err, &validationErr)
+	assert.Equal(t, "no-frontmatter.md", validationErr.Path)
+	assert.Empty(t, validationErr.Format)
+	assert.Equal(t, "no-frontmatter.md: frontmatter does not match the schema: missing properties 'title', 'slug'", validationErr.Error())
+}
+
+func TestCompileSchema(t *testing.T) {
+	_, err := mdfm.CompileSchema(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "invalid.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"type": "text"}`), 0o600))
+	_, err = mdfm.CompileSchema(path)
+	require.Error(t, err)
+}
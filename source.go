@@ -0,0 +1,214 @@
+package mdfm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+
+	"github.com/sushichan044/mdfm/internal/gitignore"
+)
+
+// source abstracts where Markdown files are discovered and read from,
+// either the OS file system or an fs.FS given with WithFS.
+type source struct {
+	// fsys is nil when reading from the OS file system.
+	fsys fs.FS
+	// root is the OS directory that paths are relative to. Empty means the current working directory.
+	root string
+
+	gitIgnore bool
+}
+
+func newSource(o *options) (*source, error) {
+	if o.fsys == nil {
+		return &source{root: o.root, gitIgnore: o.gitIgnore}, nil
+	}
+
+	fsys := o.fsys
+	if o.root != "" {
+		sub, err := fs.Sub(fsys, path.Clean(filepath.ToSlash(o.root)))
+		if err != nil {
+			return nil, err
+		}
+		fsys = sub
+	}
+	return &source{fsys: fsys, gitIgnore: o.gitIgnore}, nil
+}
+
+// glob returns the paths matched by patterns, in the order each reports them.
+func (s *source) glob(ctx context.Context, patterns []string) ([]string, error) {
+	var matched []string
+	err := s.each(ctx, patterns, func(p string) error {
+		matched = append(matched, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return matched, nil
+}
+
+// each executes glob pattern matching while respecting Git ignore rules, and calls fn with every
+// matched path as soon as the walk finds it. Files that are excluded by .gitignore, global Git
+// excludes, or local Git excludes are skipped, ensuring only relevant files are processed.
+//
+// Every pattern is matched in turn, and paths are reported in pattern order without duplicates.
+// Patterns prefixed with "!" are not matched; instead, any path they match is dropped.
+// All patterns are validated before the walk starts.
+//
+// For the OS file system without a root, patterns are resolved against the current working directory.
+// Otherwise they are resolved inside the root (or fsys) and the reported paths are relative to it.
+//
+// The walk stops as soon as fn returns an error or ctx is done, and that error is returned.
+//
+// The function uses doublestar for advanced glob pattern support and
+// integrates with Git ignore functionality for seamless filtering.
+func (s *source) each(ctx context.Context, patterns []string, fn func(p string) error) error {
+	includes, excludes, err := splitPatterns(patterns)
+	if err != nil {
+		return err
+	}
+
+	var gi *gitignore.Matcher
+	if s.gitIgnore {
+		if gi, err = s.ignoreMatcher(); err != nil {
+			return err
+		}
+	}
+
+	seen := make(map[string]struct{})
+	for _, pattern := range includes {
+		err = s.match(ctx, pattern, func(p string) error {
+			if _, ok := seen[p]; ok {
+				return nil
+			}
+			seen[p] = struct{}{}
+
+			if isExcluded(p, excludes) || (gi != nil && gi.IsIgnored(s.ignorePath(p))) {
+				return nil
+			}
+			return fn(p)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitPatterns separates the patterns to match from the "!" patterns of paths to drop,
+// and validates them.
+func splitPatterns(patterns []string) ([]string, []string, error) {
+	var includes, excludes []string
+	for _, pattern := range patterns {
+		exclude, isExclude := strings.CutPrefix(pattern, "!")
+		if !doublestar.ValidatePattern(path.Clean(filepath.ToSlash(exclude))) {
+			return nil, nil, fmt.Errorf("%w: %s", doublestar.ErrBadPattern, pattern)
+		}
+		if isExclude {
+			excludes = append(excludes, path.Clean(filepath.ToSlash(exclude)))
+			continue
+		}
+		includes = append(includes, pattern)
+	}
+	return includes, excludes, nil
+}
+
+// match calls fn with the paths matching pattern, as reported by each.
+func (s *source) match(ctx context.Context, pattern string, fn func(p string) error) error {
+	switch {
+	case s.fsys != nil:
+		return walk(ctx, s.fsys, path.Clean(pattern), fn)
+	case s.root != "":
+		return walk(ctx, os.DirFS(s.root), path.Clean(filepath.ToSlash(pattern)), func(p string) error {
+			return fn(filepath.FromSlash(p))
+		})
+	default:
+		// Like doublestar.FilepathGlob, the walk starts from the directory part of the pattern
+		// without meta characters.
+		base, rest := doublestar.SplitPattern(filepath.ToSlash(filepath.Clean(pattern)))
+		if rest != "" && rest != "." && rest != ".." {
+			return walk(ctx, os.DirFS(base), rest, func(p string) error {
+				return fn(filepath.FromSlash(path.Join(base, p)))
+			})
+		}
+
+		matched, err := doublestar.FilepathGlob(pattern)
+		if err != nil {
+			return err
+		}
+		for _, p := range matched {
+			if err = fn(p); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// walk calls fn with the paths in fsys matching pattern, checking ctx for every match.
+func walk(ctx context.Context, fsys fs.FS, pattern string, fn func(p string) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return doublestar.GlobWalk(fsys, pattern, func(p string, _ fs.DirEntry) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return fn(p)
+	})
+}
+
+// isExcluded reports whether p matches any of the exclude patterns.
+func isExcluded(p string, excludes []string) bool {
+	slashed := filepath.ToSlash(p)
+	for _, exclude := range excludes {
+		// Patterns have been validated beforehand, so Match never fails.
+		if ok, _ := doublestar.Match(exclude, slashed); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *source) ignoreMatcher() (*gitignore.Matcher, error) {
+	switch {
+	case s.fsys != nil:
+		return gitignore.NewFS(s.fsys)
+	case s.root != "":
+		return gitignore.New(s.root)
+	default:
+		return gitignore.NewFromCWD()
+	}
+}
+
+// ignorePath returns the path of p as expected by the Git ignore matcher.
+func (s *source) ignorePath(p string) string {
+	if s.fsys != nil {
+		return p
+	}
+	return filepath.Join(s.root, p)
+}
+
+// open opens the matched file at p for reading.
+func (s *source) open(p string) (io.ReadCloser, error) {
+	if s.fsys != nil {
+		return s.fsys.Open(p)
+	}
+	return os.Open(filepath.Join(s.root, p))
+}
+
+// stat returns the metadata of the matched file at p.
+func (s *source) stat(p string) (fs.FileInfo, error) {
+	if s.fsys != nil {
+		return fs.Stat(s.fsys, p)
+	}
+	return os.Stat(filepath.Join(s.root, p))
+}
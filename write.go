@@ -0,0 +1,208 @@
+package mdfm
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+
+	"github.com/sushichan044/mdfm/internal/markdown"
+	"github.com/sushichan044/mdfm/internal/tree"
+)
+
+// Update parses the Markdown file at path, lets fn modify its frontmatter and writes the
+// document back with WriteFile. The file is left untouched if fn returns an error.
+//
+// Example usage:
+//
+//	err := Update("posts/hello.md", func(meta *Article) error {
+//		meta.Draft = false
+//		return nil
+//	})
+//
+// opts are used to parse the file, e.g. WithUnifiedDecoding or WithFormats.
+// Update cannot be used with WithoutBody, as the body is written back.
+func Update[T any](path string, fn func(*T) error, opts ...Option) error {
+	o, err := newOptions(opts...)
+	if err != nil {
+		return err
+	}
+	if o.skipBody {
+		return errors.New("mdfm: Update cannot write files read with WithoutBody")
+	}
+
+	doc, err := ParseFile[T](path, opts...)
+	if err != nil {
+		return err
+	}
+	if fnErr := fn(&doc.FrontMatter); fnErr != nil {
+		return fnErr
+	}
+	return WriteFile(path, doc)
+}
+
+// WriteFile writes doc, rendered by Marshal, to the file at path.
+//
+// The file is replaced atomically: the document is written to a temporary file in the same
+// directory, which is then renamed to path. The permissions of an existing file are kept.
+// Failures to write the file wrap ErrIO.
+func WriteFile[T any](path string, doc *MarkdownDocument[T]) error {
+	data, err := Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// Marshal renders doc as a Markdown document: its frontmatter followed by Body, byte-for-byte.
+//
+// The frontmatter is encoded in doc.Format, or as YAML if the document has none, with field
+// names taken from the same struct tags as when decoding. For documents returned by Parse and
+// friends, the frontmatter is edited in place to keep the diff small:
+//
+//   - the delimiter lines are kept, and so is the frontmatter text if nothing changed,
+//   - keys keep their order, new keys are appended (with OrderedMap, the order of its keys
+//     is used instead),
+//   - keys that T cannot represent are kept (see WithStrictDecoding), even when doc.Format
+//     is changed to convert the frontmatter to another format,
+//   - YAML and TOML pairs are only rendered again if their value changed, so comments,
+//     spacing and line endings are kept too, and YAML quoting styles are kept for changed values.
+//
+// When T is a struct, fields whose key is not in the original frontmatter are only added if
+// their value is not empty (null, false, zero, "" or an empty list or mapping), so that a
+// document without frontmatter and a zero FrontMatter is rendered without frontmatter.
+// Only the built-in formats can be encoded.
+func Marshal[T any](doc *MarkdownDocument[T]) ([]byte, error) {
+	format := doc.Format
+	if format == "" {
+		format = FormatYAML
+	}
+
+	tag := doc.origin.encodingTag(format)
+	encoded, err := tree.Encode(doc.FrontMatter, tag)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode %s frontmatter: %w", format, err)
+	}
+	value, ok := encoded.(*tree.OrderedMap)
+	if !ok && encoded != nil {
+		return nil, fmt.Errorf("cannot encode %T as frontmatter: not a mapping", doc.FrontMatter)
+	}
+
+	// The original block is merged into even when converting to another format,
+	// so that the keys T cannot represent are not lost.
+	source := doc.origin.block(doc.RawFrontMatter)
+	old, merged, err := mergeFrontMatter[T](source, value, doc.origin.fieldTag)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil && merged.Len() == 0 {
+		return slices.Clone(doc.Body), nil
+	}
+
+	var block *markdown.Block
+	if source != nil && source.Format.Name == format {
+		block = source
+	}
+	front, err := markdown.Encode(format, block, old, merged)
+	if err != nil {
+		return nil, err
+	}
+	return append(front, doc.Body...), nil
+}
+
+// block rebuilds the frontmatter block the document was parsed from.
+// It returns nil if the document had no frontmatter or its format cannot be encoded.
+func (o origin) block(raw []byte) *markdown.Block {
+	f := markdown.BuiltinFormat(o.format)
+	if f == nil {
+		return nil
+	}
+	return &markdown.Block{Format: f, Raw: raw, Head: o.head, Tail: o.tail}
+}
+
+// encodingTag returns the struct tag used to encode the frontmatter in format.
+func (o origin) encodingTag(format string) string {
+	if o.fieldTag == UnifiedTag || (o.format == format && o.fieldTag != "") {
+		return o.fieldTag
+	}
+	// The built-in formats are named after their struct tag.
+	return format
+}
+
+// mergeFrontMatter merges value into the frontmatter decoded from block, keeping the keys
+// that T cannot represent. It returns the original tree along with the merged one.
+// A nil block is treated as an empty frontmatter.
+func mergeFrontMatter[T any](block *markdown.Block, value *tree.OrderedMap, tag string) (any, *tree.OrderedMap, error) {
+	old := tree.NewOrderedMap()
+	var unknown []string
+	if block != nil {
+		if err := markdown.DecodeOrdered(block, old); err != nil {
+			return nil, nil, err
+		}
+
+		var err error
+		unknown, err = markdown.UnknownKeys(block, reflect.TypeFor[T](), tag)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	keep := func(path string) bool {
+		_, found := slices.BinarySearch(unknown, path)
+		return found
+	}
+
+	if value == nil {
+		value = tree.NewOrderedMap()
+	}
+	// Only the keys of maps are set on purpose; struct fields are always encoded.
+	// The keys of an OrderedMap are in the order chosen by the caller.
+	t := reflect.TypeFor[T]()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	isOrderedMap := t == reflect.TypeFor[OrderedMap]()
+	merged, _ := tree.Merge(old, value, tree.MergeOptions{
+		Keep:         keep,
+		OmitEmpty:    t.Kind() == reflect.Struct && !isOrderedMap,
+		UpdatedOrder: isOrderedMap,
+	}).(*tree.OrderedMap)
+	return old, merged, nil
+}
+
+// writeFileAtomic replaces the file at path with data through a temporary file and a rename.
+func writeFileAtomic(path string, data []byte) error {
+	perm := fs.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return wrapIOError(err)
+	}
+	cleanup := func(cause error) error {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return wrapIOError(cause)
+	}
+
+	if _, wErr := tmp.Write(data); wErr != nil {
+		return cleanup(wErr)
+	}
+	if sErr := tmp.Sync(); sErr != nil {
+		return cleanup(sErr)
+	}
+	if cErr := tmp.Chmod(perm); cErr != nil {
+		return cleanup(cErr)
+	}
+	if cErr := tmp.Close(); cErr != nil {
+		return cleanup(cErr)
+	}
+	if rErr := os.Rename(tmp.Name(), path); rErr != nil {
+		return cleanup(rErr)
+	}
+	return nil
+}
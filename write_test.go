@@ -0,0 +1,224 @@
+package mdfm_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/mdfm"
+)
+
+type postMetadata struct {
+	Title string   `json:"title" mdfm:"title" toml:"title" yaml:"title"`
+	Draft bool     `json:"draft" mdfm:"draft" toml:"draft" yaml:"draft"`
+	Tags  []string `json:"tags"  mdfm:"tags"  toml:"tags"  yaml:"tags"`
+}
+
+func writeTestFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "post.md")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestUpdate(t *testing.T) {
+	body := "# Hello\n\n  Body with trailing spaces   \n\n---\nnot frontmatter\n"
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "yaml keeps comments, order and unknown keys",
+			input:    "---\n# Metadata\nweight: 10\ntitle: Hello # the title\ndraft: true\ntags: [a]\n---\n" + body,
+			expected: "---\n# Metadata\nweight: 10\ntitle: Bye # the title\ndraft: false\ntags: [a, b]\n---\n" + body,
+		},
+		{
+			name:     "toml",
+			input:    "+++\ntitle = \"Hello\"\nweight = 10\ndraft = true\ntags = [\"a\"]\n+++\n" + body,
+			expected: "+++\ntitle = \"Bye\"\nweight = 10\ndraft = false\ntags = [\"a\", \"b\"]\n+++\n" + body,
+		},
+		{
+			name:     "toml keeps comments and local dates",
+			input:    "+++\n# Metadata\ntitle = \"Hello\" # the title\ndate = 2023-05-01\nat = 10:30:00\ndraft = true\n+++\n" + body,
+			expected: "+++\n# Metadata\ntitle = \"Bye\" # the title\ndate = 2023-05-01\nat = 10:30:00\ndraft = false\ntags = [\"b\"]\n+++\n" + body,
+		},
+		{
+			name:     "json",
+			input:    "{\n  \"title\": \"Hello\",\n  \"draft\": true,\n  \"tags\": [\"a\"],\n  \"weight\": 10\n}\n\n" + body,
+			expected: "{\n  \"title\": \"Bye\",\n  \"draft\": false,\n  \"tags\": [\n    \"a\",\n    \"b\"\n  ],\n  \"weight\": 10\n}\n\n" + body,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTestFile(t, tt.input)
+
+			err := mdfm.Update(path, func(meta *postMetadata) error {
+				meta.Title = "Bye"
+				meta.Draft = false
+				meta.Tags = append(meta.Tags, "b")
+				return nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, readTestFile(t, path))
+		})
+	}
+
+	t.Run("yaml keeps untouched lines", func(t *testing.T) {
+		input := "---\ntitle: \"Hello\"   # the title\nweight:   10\ndesc: >\n  folded\n  text\ndraft: true\n---\n" + body
+		path := writeTestFile(t, input)
+
+		err := mdfm.Update(path, func(meta *postMetadata) error {
+			meta.Draft = false
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, strings.Replace(input, "draft: true", "draft: false", 1), readTestFile(t, path))
+	})
+
+	t.Run("yaml keeps CRLF line endings", func(t *testing.T) {
+		input := "---\r\ntitle: Hello\r\nweight:   10\r\ndraft: true\r\n---\r\nBody\r\n"
+		path := writeTestFile(t, input)
+
+		err := mdfm.Update(path, func(meta *postMetadata) error {
+			meta.Draft = false
+			meta.Tags = []string{"a"}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(
+			t,
+			"---\r\ntitle: Hello\r\nweight:   10\r\ndraft: false\r\ntags:\r\n  - a\r\n---\r\nBody\r\n",
+			readTestFile(t, path),
+		)
+	})
+
+	t.Run("unified decoding", func(t *testing.T) {
+		path := writeTestFile(t, "+++\ntitle = \"Hello\"\n+++\n"+body)
+
+		err := mdfm.Update(path, func(meta *postMetadata) error {
+			meta.Draft = true
+			return nil
+		}, mdfm.WithUnifiedDecoding())
+		require.NoError(t, err)
+		assert.Equal(t, "+++\ntitle = \"Hello\"\ndraft = true\n+++\n"+body, readTestFile(t, path))
+	})
+
+	t.Run("callback error leaves the file untouched", func(t *testing.T) {
+		input := "---\ntitle: Hello\n---\n" + body
+		path := writeTestFile(t, input)
+		errStop := errors.New("stop")
+
+		err := mdfm.Update(path, func(meta *postMetadata) error {
+			meta.Title = "Bye"
+			return errStop
+		})
+		require.ErrorIs(t, err, errStop)
+		assert.Equal(t, input, readTestFile(t, path))
+	})
+
+	t.Run("without body", func(t *testing.T) {
+		input := "---\ntitle: Hello\n---\n" + body
+		path := writeTestFile(t, input)
+
+		err := mdfm.Update(path, func(meta *postMetadata) error {
+			meta.Title = "New"
+			return nil
+		}, mdfm.WithoutBody())
+		require.EqualError(t, err, "mdfm: Update cannot write files read with WithoutBody")
+		assert.Equal(t, input, readTestFile(t, path))
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		err := mdfm.Update(filepath.Join(t.TempDir(), "missing.md"), func(*postMetadata) error { return nil })
+		require.ErrorIs(t, err, mdfm.ErrIO)
+	})
+}
+
+func TestWriteFile(t *testing.T) {
+	input := "---\ntitle: Hello\n---\nBody"
+	path := writeTestFile(t, input)
+	require.NoError(t, os.Chmod(path, 0o640))
+
+	doc, err := mdfm.ParseFile[map[string]any](path)
+	require.NoError(t, err)
+	doc.FrontMatter["title"] = "Bye"
+	require.NoError(t, mdfm.WriteFile(path, doc))
+
+	assert.Equal(t, "---\ntitle: Bye\n---\nBody", readTestFile(t, path))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary file is left behind")
+}
+
+func TestMarshal(t *testing.T) {
+	t.Run("unchanged document is identical", func(t *testing.T) {
+		input := "---\ntitle:   Hello   # spacing is kept\ntags: [a]\n---\nBody"
+		doc, err := mdfm.ParseBytes[postMetadata]([]byte(input))
+		require.NoError(t, err)
+
+		data, err := mdfm.Marshal(doc)
+		require.NoError(t, err)
+		assert.Equal(t, input, string(data))
+	})
+
+	t.Run("document without frontmatter", func(t *testing.T) {
+		doc, err := mdfm.ParseBytes[postMetadata]([]byte("# Body"))
+		require.NoError(t, err)
+
+		data, err := mdfm.Marshal(doc)
+		require.NoError(t, err)
+		assert.Equal(t, "# Body", string(data))
+
+		doc.FrontMatter.Title = "New"
+		data, err = mdfm.Marshal(doc)
+		require.NoError(t, err)
+		assert.Equal(t, "---\ntitle: New\n---\n# Body", string(data))
+	})
+
+	t.Run("empty values are added to maps", func(t *testing.T) {
+		doc, err := mdfm.ParseBytes[mdfm.OrderedMap]([]byte("---\ntitle: Hello\n---\nBody"))
+		require.NoError(t, err)
+
+		doc.FrontMatter.Set("draft", false)
+		data, err := mdfm.Marshal(doc)
+		require.NoError(t, err)
+		assert.Equal(t, "---\ntitle: Hello\ndraft: false\n---\nBody", string(data))
+	})
+
+	t.Run("format conversion", func(t *testing.T) {
+		doc, err := mdfm.ParseBytes[postMetadata]([]byte("---\ntitle: Hello\nweight: 10\ntags: [a]\n---\nBody"))
+		require.NoError(t, err)
+
+		doc.Format = mdfm.FormatTOML
+		data, err := mdfm.Marshal(doc)
+		require.NoError(t, err)
+		assert.Equal(t, "+++\ntitle = \"Hello\"\nweight = 10\ntags = [\"a\"]\n+++\nBody", string(data))
+	})
+
+	t.Run("non-mapping frontmatter", func(t *testing.T) {
+		doc := &mdfm.MarkdownDocument[[]string]{FrontMatter: []string{"a"}}
+		_, err := mdfm.Marshal(doc)
+		require.Error(t, err)
+	})
+}